@@ -0,0 +1,163 @@
+package main
+
+//
+// api.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"mikrotik-exporter/internal/collector"
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/routeros"
+	"mikrotik-exporter/routeros/proto"
+)
+
+var ErrInvalidAPIArgs = errors.New("usage: api <device> <command> [args...]")
+
+// runAPICommand run api sentence `args[1:]` on device `args[0]` and print reply in `format`.
+func runAPICommand(cfg *config.Config, args []string, format string) error {
+	if len(args) < 2 { //nolint:mnd
+		return ErrInvalidAPIArgs
+	}
+
+	name, sentence := args[0], args[1:]
+
+	idx := slices.IndexFunc(cfg.Devices, func(d config.Device) bool { return d.Name == name })
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", config.ErrUnknownDevice, name)
+	}
+
+	client, err := collector.Connect(context.Background(), cfg.Devices[idx])
+	if err != nil {
+		return fmt.Errorf("connect error: %w", err)
+	}
+
+	defer client.Close()
+
+	reply, err := client.RunArgs(sentence)
+	if err != nil {
+		return fmt.Errorf("run error: %w", err)
+	}
+
+	switch format {
+	case "json":
+		return writeReplyJSON(os.Stdout, reply)
+	case "raw":
+		_, err = fmt.Fprintln(os.Stdout, reply.String())
+	default:
+		err = writeReplyTable(os.Stdout, reply, proplist(sentence))
+	}
+
+	if err != nil {
+		return fmt.Errorf("write reply error: %w", err)
+	}
+
+	return nil
+}
+
+// proplist return list of properties from `=.proplist=` argument.
+func proplist(sentence []string) []string {
+	for _, word := range sentence {
+		if props, ok := strings.CutPrefix(word, "=.proplist="); ok {
+			return strings.Split(props, ",")
+		}
+	}
+
+	return nil
+}
+
+// writeReplyTable write `!re` sentences as table; columns are ordered as in `columns` and
+// then alphabetically. Content of `!done` is printed below table.
+func writeReplyTable(w io.Writer, reply *routeros.Reply, columns []string) error {
+	keys := make(map[string]struct{})
+
+	for _, re := range reply.Re {
+		for k := range re.Map {
+			if !slices.Contains(columns, k) {
+				keys[k] = struct{}{}
+			}
+		}
+	}
+
+	rest := make([]string, 0, len(keys))
+	for k := range keys {
+		rest = append(rest, k)
+	}
+
+	slices.Sort(rest)
+
+	columns = append(columns, rest...)
+
+	if len(reply.Re) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+
+		for _, re := range reply.Re {
+			values := make([]string, 0, len(columns))
+			for _, c := range columns {
+				values = append(values, re.Map[c])
+			}
+
+			fmt.Fprintln(tw, strings.Join(values, "\t"))
+		}
+
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("flush error: %w", err)
+		}
+	}
+
+	if reply.Done != nil && len(reply.Done.Map) > 0 {
+		fmt.Fprintf(w, "\n!done: %s\n", formatPairs(reply.Done))
+	}
+
+	return nil
+}
+
+func formatPairs(sen *proto.Sentence) string {
+	pairs := sen.AsList()
+	slices.SortFunc(pairs, func(a, b proto.Pair) int { return strings.Compare(a.Key, b.Key) })
+
+	res := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		res = append(res, p.String())
+	}
+
+	return strings.Join(res, " ")
+}
+
+func writeReplyJSON(w io.Writer, reply *routeros.Reply) error {
+	res := struct {
+		Done map[string]string   `json:"done"`
+		Re   []map[string]string `json:"re"`
+	}{
+		Re: make([]map[string]string, 0, len(reply.Re)),
+	}
+
+	for _, re := range reply.Re {
+		res.Re = append(res.Re, re.Map)
+	}
+
+	if reply.Done != nil {
+		res.Done = reply.Done.Map
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(res); err != nil {
+		return fmt.Errorf("encode json error: %w", err)
+	}
+
+	return nil
+}
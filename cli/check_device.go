@@ -0,0 +1,112 @@
+package main
+
+//
+// check_device.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"mikrotik-exporter/internal/collector"
+	"mikrotik-exporter/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// checkDevice connect to device, run all enabled collectors and print results.
+// Return true when all collectors succeeded.
+func checkDevice(cfg *config.Config, name string, dumpMetrics bool) bool {
+	// check is limited by device collect timeout in CheckDevice
+	ctx := context.Background()
+
+	result, err := collector.CheckDevice(ctx, cfg, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check device %s error: %s\n", name, err)
+
+		return false
+	}
+
+	dev := &result.Device
+	fv := &dev.FirmwareVersion
+
+	fmt.Printf("\nDevice:    %s (%s:%s)\n", dev.Name, dev.Address, dev.Port)
+	fmt.Printf("Firmware:  %d.%d.%d (%s)\n", fv.Major, fv.Minor, fv.Patch, fv.Architecture)
+	fmt.Printf("Timezone:  %s\n\n", dev.Timezone)
+
+	success := true
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(tw, "COLLECTOR\tSTATUS\tDURATION\tSERIES\tERROR")
+
+	for _, res := range result.Collectors {
+		status, errMsg := "ok", ""
+		if res.Err != nil {
+			status, errMsg = "failed", strings.ReplaceAll(res.Err.Error(), "\n", "; ")
+			success = false
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", res.Name, status,
+			res.Duration.Round(time.Millisecond), len(res.Metrics), errMsg)
+	}
+
+	_ = tw.Flush()
+
+	fmt.Println()
+
+	if dumpMetrics {
+		if err := writeMetrics(os.Stdout, result.Collectors); err != nil {
+			fmt.Fprintf(os.Stderr, "write metrics error: %s\n", err)
+
+			return false
+		}
+	}
+
+	return success
+}
+
+// writeMetrics write collected metrics in prometheus text format.
+func writeMetrics(w io.Writer, results []collector.CollectorCheckResult) error {
+	registry := prometheus.NewRegistry()
+
+	var mc metricsList
+	for _, res := range results {
+		mc = append(mc, res.Metrics...)
+	}
+
+	if err := registry.Register(mc); err != nil {
+		return fmt.Errorf("register metrics error: %w", err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		return fmt.Errorf("gather metrics error: %w", err)
+	}
+
+	for _, mf := range families {
+		if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+			return fmt.Errorf("encode metrics error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// metricsList is unchecked prometheus.Collector that return already collected metrics.
+type metricsList []prometheus.Metric
+
+func (m metricsList) Describe(_ chan<- *prometheus.Desc) {}
+
+func (m metricsList) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range m {
+		ch <- metric
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+//
+// influx.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"mikrotik-exporter/internal/collector"
+	"mikrotik-exporter/internal/influx"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newInfluxHandler create handler that render devices metrics in Influx line protocol.
+// Support the same filters as metrics handler.
+func newInfluxHandler(coll collector.Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fcoll, err := coll.WithFilter(filterFromQuery(r.URL.Query()))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		registry := prometheus.NewRegistry()
+		if err := registry.Register(fcoll); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		mfs, err := registry.Gather()
+		if err != nil {
+			// gather return partial result on errors
+			slog.Warn("gather metrics error", "err", err)
+		}
+
+		w.Header().Set("Content-Type", influx.ContentType)
+
+		if err := influx.Write(w, mfs, time.Now()); err != nil {
+			slog.Error("write influx metrics error", "err", err)
+		}
+	})
+}
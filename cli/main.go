@@ -1,20 +1,26 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"mikrotik-exporter/internal/collector"
 	"mikrotik-exporter/internal/collectors"
 	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/otlp"
+	"mikrotik-exporter/internal/remotewrite"
+	"mikrotik-exporter/internal/tracing"
 
 	"github.com/coreos/go-systemd/v22/daemon"
 	"github.com/prometheus/client_golang/prometheus"
@@ -45,6 +51,18 @@ var (
 
 	listCollectors = flag.Bool("list-collectors", false, "list available collectors")
 
+	checkDeviceName = flag.String("test-device", "",
+		"connect to device, run enabled collectors, print summary and exit")
+	checkDeviceDump = flag.Bool("test-device-dump", false, "print metrics collected by -test-device")
+
+	apiFormat = flag.String("api-format", "table", "output format for api command: table/json/raw")
+
+	shardIndex = flag.Int("shard-index", 0, "index of shard handled by this instance (0..shard-count-1)")
+	shardCount = flag.Int("shard-count", 0, "total number of shards; overwrite sharding section from config file")
+
+	shutdownTimeout = flag.Int("shutdown-timeout", 30, //nolint:mnd
+		"seconds to wait for each shutdown phase (http server, collector, tracing)")
+
 	withAllCollectors = flag.Bool("with-all", false, "enable all collectors")
 )
 
@@ -86,8 +104,35 @@ func main() {
 
 	config.SetupLogging(logLevel, logFormat)
 
+	isAPICommand := flag.Arg(0) == "api"
+
+	if *configFile == "" && *device == "" {
+		if *checkDeviceName != "" {
+			*device = *checkDeviceName
+		} else if isAPICommand {
+			*device = flag.Arg(1)
+		}
+	}
+
 	cfg := loadConfig()
 
+	if isAPICommand {
+		if err := runAPICommand(cfg, flag.Args()[1:], *apiFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "api command error: %s\n", err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if *checkDeviceName != "" {
+		if !checkDevice(cfg, *checkDeviceName, *checkDeviceDump) {
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
 	startServer(cfg)
 }
 
@@ -109,18 +154,17 @@ func loadConfig() *config.Config {
 		os.Exit(3) //nolint:mnd
 	}
 
-	updateConfigFromFlags(cfg)
+	if err := updateConfigFromFlags(cfg); err != nil {
+		slog.Default().Error("invalid configuration", "error", err)
+
+		os.Exit(3) //nolint:mnd
+	}
 
 	return cfg
 }
 
 func loadConfigFromFile() (*config.Config, error) {
-	b, err := os.ReadFile(*configFile)
-	if err != nil {
-		return nil, fmt.Errorf("read file error: %w", err)
-	}
-
-	cfg, err := config.Load(bytes.NewReader(b), collectors.AvailableCollectorsNames())
+	cfg, err := config.LoadFile(*configFile, collectors.AvailableCollectorsNames())
 	if err != nil {
 		return nil, fmt.Errorf("load error: %w", err)
 	}
@@ -177,12 +221,53 @@ func startServer(cfg *config.Config) {
 		logger.Warn("enable systemd watchdog error", "err", err)
 	}
 
-	h, err := createMetricsHandler(cfg)
+	// ctx is canceled on SIGTERM/SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	var tracer *tracing.Tracer
+
+	if cfg.Tracing != nil {
+		t, err := setupTracing(cfg.Tracing)
+		if err != nil {
+			logger.Error("setup tracing error", "err", err)
+
+			os.Exit(1)
+		}
+
+		tracer = t
+	}
+
+	coll := collector.NewCollector(cfg)
+
+	h, err := createMetricsHandler(coll)
 	if err != nil {
 		panic(err)
 	}
 
+	if cfg.RemoteWrite != nil {
+		if err := startRemoteWrite(ctx, cfg.RemoteWrite, coll); err != nil {
+			logger.Error("start remote write error", "err", err)
+
+			os.Exit(1)
+		}
+	}
+
+	if cfg.OTLP != nil {
+		exporter, err := otlp.NewMetricsExporter(cfg.OTLP, coll)
+		if err != nil {
+			logger.Error("start otlp export error", "err", err)
+
+			os.Exit(1)
+		}
+
+		go exporter.Run(ctx)
+	}
+
 	http.Handle(*metricsPath, h)
+	http.Handle(strings.TrimSuffix(*metricsPath, "/")+"/influx", newInfluxHandler(coll))
+	http.Handle("/status", newStatusPageHandler(coll))
+	http.Handle("/api/v1/devices", newDevicesAPIHandler(coll))
 
 	http.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		_, _ = w.Write([]byte("ok"))
@@ -198,6 +283,14 @@ func startServer(cfg *config.Config) {
 					Address: *metricsPath,
 					Text:    "Metrics",
 				},
+				{
+					Address: strings.TrimSuffix(*metricsPath, "/") + "/influx",
+					Text:    "Metrics (Influx line protocol)",
+				},
+				{
+					Address: "/status",
+					Text:    "Status",
+				},
 			},
 		}
 
@@ -220,21 +313,59 @@ func startServer(cfg *config.Config) {
 	_, _ = daemon.SdNotify(false, "STATUS=started")
 	_, _ = daemon.SdNotify(false, daemon.SdNotifyReady)
 
-	if err := web.ListenAndServe(srv, &web.FlagConfig{
-		WebListenAddresses: &[]string{*listen},
-		WebConfigFile:      webConfig,
-	}, logger); err != nil {
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- web.ListenAndServe(srv, &web.FlagConfig{
+			WebListenAddresses: &[]string{*listen},
+			WebConfigFile:      webConfig,
+		}, logger)
+	}()
+
+	select {
+	case err := <-serveErr:
 		logger.Error("listen and serve error", "err", err)
 
 		os.Exit(1)
+	case <-ctx.Done():
 	}
+
+	shutdown(srv, coll, tracer)
 }
 
-func createMetricsHandler(cfg *config.Config) (http.Handler, error) {
-	collector := collector.NewCollector(cfg)
+// shutdown stop http server, wait for running scrapes and close connections to devices.
+func shutdown(srv *http.Server, coll collector.Collector, tracer *tracing.Tracer) {
+	logger := slog.Default()
+	logger.Info("shutting down", "timeout", *shutdownTimeout)
+
+	_, _ = daemon.SdNotify(false, daemon.SdNotifyStopping)
+	_, _ = daemon.SdNotify(false, "STATUS=stopping")
 
-	promhttp.Handler()
+	timeout := time.Duration(*shutdownTimeout) * time.Second
 
+	// each phase has own timeout so slow http shutdown do not prevent closing device connections;
+	// first stop accepting new requests and wait for running
+	shutdownPhase(logger, "http server", timeout, srv.Shutdown)
+	shutdownPhase(logger, "collector", timeout, coll.Shutdown)
+
+	if tracer != nil {
+		shutdownPhase(logger, "tracing", timeout, tracer.Shutdown)
+	}
+
+	logger.Info("stopped")
+}
+
+// shutdownPhase run `fn` with context limited by `timeout`.
+func shutdownPhase(logger *slog.Logger, name string, timeout time.Duration, fn func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		logger.Error("shutdown "+name+" error", "err", err)
+	}
+}
+
+func createMetricsHandler(collector collector.Collector) (http.Handler, error) {
 	registry := prometheus.NewRegistry()
 
 	if err := registry.Register(
@@ -252,26 +383,120 @@ func createMetricsHandler(cfg *config.Config) (http.Handler, error) {
 		return nil, fmt.Errorf("register collector error: %w", err)
 	}
 
+	opts := metricsHandlerOpts()
+	handler := promhttp.HandlerFor(registry, opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := filterFromQuery(r.URL.Query())
+		if filter.IsEmpty() {
+			handler.ServeHTTP(w, r)
+
+			return
+		}
+
+		fcollector, err := collector.WithFilter(filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		// filtered scrapes return only devices metrics
+		freg := prometheus.NewRegistry()
+		if err := freg.Register(fcollector); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		promhttp.HandlerFor(freg, opts).ServeHTTP(w, r)
+	}), nil
+}
+
+// startRemoteWrite start background pushing metrics from `collector` to remote_write endpoint.
+func startRemoteWrite(ctx context.Context, cfg *config.RemoteWrite, collector prometheus.Collector) error {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		return fmt.Errorf("register collector error: %w", err)
+	}
+
+	go remotewrite.New(cfg, registry).Run(ctx)
+
+	return nil
+}
+
+// setupTracing enable tracing with configured exporter.
+func setupTracing(cfg *config.Tracing) (*tracing.Tracer, error) {
+	var exporter tracing.Exporter
+
+	switch cfg.Exporter {
+	case config.TracingExporterFile:
+		fexp, err := tracing.NewFileExporter(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("create file exporter error: %w", err)
+		}
+
+		exporter = fexp
+	default:
+		exporter = otlp.NewTraceExporter(cfg)
+	}
+
+	return tracing.Setup(exporter), nil
+}
+
+func metricsHandlerOpts() promhttp.HandlerOpts {
 	disableCompression := strings.HasPrefix(*listen, "127.") ||
 		strings.HasPrefix(*listen, "localhost:")
 
-	return promhttp.HandlerFor(registry,
-		promhttp.HandlerOpts{
-			ErrorLog:            slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
-			ErrorHandling:       promhttp.ContinueOnError,
-			DisableCompression:  disableCompression,
-			MaxRequestsInFlight: 1,
-			EnableOpenMetrics:   true,
-		}), nil
+	return promhttp.HandlerOpts{
+		ErrorLog:           slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+		ErrorHandling:      promhttp.ContinueOnError,
+		DisableCompression: disableCompression,
+		EnableOpenMetrics:  true,
+	}
+}
+
+// filterFromQuery create filter from `device`, `collect[]` and `exclude[]` query parameters.
+func filterFromQuery(query url.Values) *collector.Filter {
+	return &collector.Filter{
+		Devices: query["device"],
+		Collect: query["collect[]"],
+		Exclude: query["exclude[]"],
+	}
 }
 
-func updateConfigFromFlags(cfg *config.Config) {
+var (
+	ErrInvalidShardIndex = errors.New("shard-index must be in range 0..shard-count-1")
+	ErrMissingShardCount = errors.New("shard-index require shard-count")
+)
+
+func updateConfigFromFlags(cfg *config.Config) error {
+	shardIndexSet := false
+
 	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "shard-index" {
+			shardIndexSet = true
+		}
+
 		if strings.HasPrefix(f.Name, "with-") && f.Name != "with-all" {
 			feat := strings.TrimPrefix(f.Name, "with-")
 			cfg.Features[feat] = config.NewFeatureConf()
 		}
 	})
+
+	if shardIndexSet && *shardCount <= 0 {
+		return ErrMissingShardCount
+	}
+
+	if *shardCount > 0 {
+		if *shardIndex < 0 || *shardIndex >= *shardCount {
+			return ErrInvalidShardIndex
+		}
+
+		cfg.Sharding = &config.Sharding{Index: *shardIndex, Count: *shardCount}
+	}
+
+	return nil
 }
 
 func enableSDNotify() error {
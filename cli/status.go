@@ -0,0 +1,90 @@
+package main
+
+//
+// status.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mikrotik-exporter/internal/collector"
+)
+
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"seconds": formatSeconds,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>Mikrotik Exporter - Status</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.5em; text-align: left; vertical-align: top; }
+.connected { color: green; }
+.failed { color: red; }
+.disconnected, .unknown { color: gray; }
+.error { color: red; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Mikrotik Exporter - Status</h1>
+<p><a href="/">Home</a> | <a href="/api/v1/devices">JSON</a></p>
+<table>
+<tr>
+<th>Device</th><th>Address</th><th>State</th><th>Last scrape</th><th>Duration</th>
+<th>Firmware</th><th>Board</th><th>Errors</th><th>Collectors</th><th>Last error</th>
+</tr>
+{{range .}}
+<tr>
+<td>{{.Name}}{{if .Srv}}<br><small>srv: {{.Srv}}</small>{{end}}{{if .Profile}}<br><small>profile: {{.Profile}}</small>{{end}}</td>
+<td>{{.Address}}:{{.Port}}</td>
+<td class="{{.State}}">{{.State}}</td>
+<td>{{if not .LastScrape.IsZero}}{{.LastScrape.Format "2006-01-02 15:04:05"}}{{end}}</td>
+<td>{{seconds .LastDuration}}</td>
+<td>{{.FirmwareVersion}}{{if .Architecture}} ({{.Architecture}}){{end}}</td>
+<td>{{.Board}}</td>
+<td>{{.Errors}}</td>
+<td>{{if .Collectors}}{{range .Collectors}}<span{{if .Error}} class="failed" title="{{.Error}}"{{end}}>{{.Name}}</span> ({{seconds .Duration}})<br>{{end}}{{else}}{{range .EnabledFeatures}}{{.}}<br>{{end}}{{end}}</td>
+<td class="error">{{.LastError}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// newStatusPageHandler create handler that render html page with status of all devices.
+func newStatusPageHandler(coll collector.Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := statusPageTemplate.Execute(w, coll.DevicesStatus()); err != nil {
+			slog.Error("render status page error", "err", err)
+		}
+	})
+}
+
+// newDevicesAPIHandler create handler that return status of all devices as json.
+func newDevicesAPIHandler(coll collector.Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(coll.DevicesStatus()); err != nil {
+			slog.Error("encode devices status error", "err", err)
+		}
+	})
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64) + "s" //nolint:mnd
+}
@@ -0,0 +1,149 @@
+package collector
+
+//
+// check_device.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"mikrotik-exporter/internal/collectors"
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/metrics"
+	"mikrotik-exporter/routeros"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// CollectorCheckResult is result of running one collector against device.
+	CollectorCheckResult struct {
+		Err      error
+		Name     string
+		Metrics  []prometheus.Metric
+		Duration time.Duration
+	}
+
+	// DeviceCheckResult is result of checking device.
+	DeviceCheckResult struct {
+		Device     config.Device
+		Collectors []CollectorCheckResult
+	}
+)
+
+// Connect dial and login to `device`. Caller is responsible for closing client.
+func Connect(ctx context.Context, device config.Device) (*routeros.Client, error) {
+	dc := newDeviceCollector(device, nil)
+
+	return dc.connect(ctx)
+}
+
+// CheckDevice connect to device `name` defined in `cfg`, load device information and run
+// each enabled collector one by one.
+func CheckDevice(ctx context.Context, cfg *config.Config, name string) (*DeviceCheckResult, error) {
+	idx := slices.IndexFunc(cfg.Devices, func(d config.Device) bool { return d.Name == name })
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: %s", config.ErrUnknownDevice, name)
+	}
+
+	device := cfg.Devices[idx]
+	if device.Srv != nil {
+		return nil, collectors.NotSupportedError("checking srv-defined device")
+	}
+
+	feat := cfg.DeviceFeatures(name)
+	names := feat.FeatureNames()
+	slices.Sort(names)
+
+	drcs := make([]deviceCollectorRC, 0, len(names))
+
+	for _, n := range names {
+		col := collectors.InstanateCollector(n)
+		if col == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCollector, n)
+		}
+
+		drcs = append(drcs, deviceCollectorRC{col, n, feat.ConfigFor(n)})
+	}
+
+	dc := newDeviceCollector(device, drcs)
+
+	// check is limited by collect timeout like regular scrape
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(dc.device.CollectTimeout)*time.Second)
+	defer cancel()
+
+	client, err := dc.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connect error: %w", err)
+	}
+
+	defer client.Close()
+
+	// abort command waiting for unresponsive device
+	stop := context.AfterFunc(ctx, client.Close)
+	defer stop()
+
+	if err := dc.getVersion(client); err != nil {
+		return nil, errors.Join(err, ctx.Err())
+	}
+
+	result := &DeviceCheckResult{
+		Device:     dc.device,
+		Collectors: make([]CollectorCheckResult, 0, len(drcs)),
+	}
+
+	logger := config.LogFromCtx(ctx)
+
+	for _, drc := range drcs {
+		ch := make(chan prometheus.Metric)
+		done := make(chan []prometheus.Metric)
+
+		go func() {
+			var res []prometheus.Metric
+			for m := range ch {
+				res = append(res, m)
+			}
+
+			done <- res
+		}()
+
+		cctx := metrics.NewCollectorContext(ch, &dc.device, client, drc.name,
+			logger.With("collector", drc.name), drc.featureConf)
+
+		begin := time.Now()
+		err := checkCollector(drc.collector, &cctx)
+		duration := time.Since(begin)
+
+		if err != nil {
+			err = errors.Join(err, ctx.Err())
+		}
+
+		close(ch)
+
+		result.Collectors = append(result.Collectors, CollectorCheckResult{
+			Name:     drc.name,
+			Err:      err,
+			Metrics:  <-done,
+			Duration: duration,
+		})
+	}
+
+	return result, nil
+}
+
+// checkCollector run collector; panic is reported as collector error.
+func checkCollector(col collectors.RouterOSCollector, cctx *metrics.CollectorContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrCollectorPanic, r)
+		}
+	}()
+
+	return col.Collect(cctx) //nolint:wrapcheck
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDevice(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+		},
+		Features: config.Features{"resource": config.NewFeatureConf()},
+	}
+
+	result, err := CheckDevice(context.Background(), cfg, "dev1")
+	require.NoError(t, err)
+
+	assert.Equal(t, config.FirmwareVersion{Major: 7, Minor: 15, Patch: 2, Architecture: "arm64"},
+		result.Device.FirmwareVersion)
+	assert.Equal(t, "Europe/Warsaw", result.Device.Timezone)
+
+	require.Len(t, result.Collectors, 1)
+	assert.Equal(t, "resource", result.Collectors[0].Name)
+	require.NoError(t, result.Collectors[0].Err)
+	assert.NotEmpty(t, result.Collectors[0].Metrics)
+
+	_, err = CheckDevice(context.Background(), cfg, "unknown")
+	require.ErrorIs(t, err, config.ErrUnknownDevice)
+}
+
+type panicCollector struct{}
+
+func (panicCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (panicCollector) Collect(*metrics.CollectorContext) error {
+	panic("boom")
+}
+
+func TestCheckCollectorPanic(t *testing.T) {
+	ch := make(chan prometheus.Metric)
+	cctx := metrics.NewCollectorContext(ch, &config.Device{}, nil, "panic", slog.Default(), config.NewFeatureConf())
+
+	err := checkCollector(panicCollector{}, &cctx)
+	require.ErrorIs(t, err, ErrCollectorPanic)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestCheckDeviceTimeout(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+	fd.setDelay(700 * time.Millisecond)
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass", CollectTimeout: 1},
+		},
+		Features: config.Features{"resource": config.NewFeatureConf()},
+	}
+
+	begin := time.Now()
+	_, err := CheckDevice(context.Background(), cfg, "dev1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(begin), 2*time.Second)
+}
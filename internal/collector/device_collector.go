@@ -8,17 +8,24 @@ package collector
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"mikrotik-exporter/internal/collectors"
 	"mikrotik-exporter/internal/config"
 	"mikrotik-exporter/internal/metrics"
+	"mikrotik-exporter/internal/tracing"
 	"mikrotik-exporter/routeros"
 	"mikrotik-exporter/routeros/proto"
 
@@ -34,6 +41,13 @@ var scrapeCollectorErrorsDesc = prometheus.NewDesc(
 	nil,
 )
 
+var tlsCertificateDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(config.Namespace, "tls", "certificate_not_after_seconds"),
+	"mikrotik_exporter: expiration time of certificate presented by device (unix timestamp)",
+	[]string{"dev_name", "dev_address", "subject", "issuer", "fingerprint_sha256"},
+	nil,
+)
+
 type (
 	deviceCollectorRC struct {
 		collector   collectors.RouterOSCollector
@@ -47,6 +61,24 @@ type (
 		collectors []deviceCollectorRC
 		isSrv      bool
 		errors     int64
+
+		// srvTarget is target resolved from srv record that created this device; set once on
+		// creation (device.Name is later replaced by identity).
+		srvTarget string
+
+		// tlsCert is certificate presented by device on last connection.
+		tlsCert *x509.Certificate
+
+		// connected is true when last connection attempt succeeded.
+		connected bool
+		// lastCollectors keep results of collectors from last scrape.
+		lastCollectors []CollectorStatus
+
+		statusMu sync.Mutex
+		status   DeviceStatus
+
+		// collectMu serialize scrapes of the device.
+		collectMu sync.Mutex
 	}
 )
 
@@ -73,9 +105,25 @@ func newDeviceCollector(device config.Device, collectors []deviceCollectorRC) *d
 		device:     device,
 		collectors: collectors,
 		isSrv:      device.Srv != nil,
+		status:     newDeviceStatus(&device, collectors),
 	}
 }
 
+// getStatus return copy of current device status.
+func (dc *deviceCollector) getStatus() DeviceStatus {
+	dc.statusMu.Lock()
+	defer dc.statusMu.Unlock()
+
+	return dc.status
+}
+
+func (dc *deviceCollector) updateStatus(begin time.Time, duration time.Duration, err error) {
+	dc.statusMu.Lock()
+	defer dc.statusMu.Unlock()
+
+	dc.status.update(dc, begin, duration, err)
+}
+
 func (dc *deviceCollector) disconnect() {
 	// close connection for srv-defined targets
 	if dc.isSrv {
@@ -86,11 +134,46 @@ func (dc *deviceCollector) disconnect() {
 	}
 }
 
-func (dc *deviceCollector) connect(ctx context.Context) (*routeros.Client, error) {
+// removeState remove state of device kept by collectors.
+func (dc *deviceCollector) removeState() {
+	for _, drc := range dc.collectors {
+		if sc, ok := drc.collector.(collectors.DeviceStateCollector); ok {
+			sc.RemoveDevice(&dc.device)
+		}
+	}
+}
+
+// close send /quit and close cached connection. Connections used by running scrapes are
+// skipped.
+func (dc *deviceCollector) close() {
+	if !dc.collectMu.TryLock() {
+		slog.Warn("device scrape still running; connection not closed", "device", dc.device.Name)
+
+		return
+	}
+
+	defer dc.collectMu.Unlock()
+
+	if dc.cl != nil {
+		dc.cl.SetRunHook(nil)
+		dc.cl.Quit(time.Duration(dc.device.Timeout) * time.Second)
+		dc.cl = nil
+	}
+}
+
+func (dc *deviceCollector) connect(ctx context.Context) (_ *routeros.Client, err error) {
 	logger := config.LogFromCtx(ctx)
 
+	ctx, span := tracing.Start(ctx, "connect", dc.traceAttrs()...)
+	defer func() {
+		span.SetError(err)
+		span.Finish()
+	}()
+
 	// try do get connection from cache (only for non-srv)
 	if dc.cl != nil {
+		dc.cl.SetRunHook(dc.traceRunHook(ctx))
+
 		// check is connection alive
 		if reply, err := dc.cl.Run("/system/identity/print"); err == nil && len(reply.Re) > 0 {
 			return dc.cl, nil
@@ -112,11 +195,19 @@ func (dc *deviceCollector) connect(ctx context.Context) (*routeros.Client, error
 
 	logger.Debug("done dialing")
 
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			dc.tlsCert = certs[0]
+		}
+	}
+
 	client, err := routeros.NewClient(conn)
 	if err != nil {
 		return nil, fmt.Errorf("create client error: %w", err)
 	}
 
+	client.SetRunHook(dc.traceRunHook(ctx))
+
 	logger.Debug("got client, trying to login")
 
 	if err := client.Login(dc.device.User, dc.device.Password); err != nil {
@@ -142,78 +233,153 @@ func (dc *deviceCollector) connect(ctx context.Context) (*routeros.Client, error
 }
 
 func (dc *deviceCollector) dial(ctx context.Context) (net.Conn, error) {
-	var (
-		con     net.Conn
-		err     error
-		timeout = time.Duration(dc.device.Timeout) * time.Second
-	)
+	timeout := time.Duration(dc.device.Timeout) * time.Second
 
-	dialer := net.Dialer{Timeout: timeout}
-	if !dc.device.TLS {
-		con, err = dialer.DialContext(ctx, "tcp", dc.device.Address+":"+dc.device.Port)
-	} else {
-		dialer := tls.Dialer{
-			NetDialer: &dialer,
-			Config: &tls.Config{
-				InsecureSkipVerify: dc.device.Insecure, // #nosec
-			},
-		}
-		con, err = dialer.DialContext(ctx, "tcp", dc.device.Address+":"+dc.device.Port)
+	dialer, err := newDialer(&dc.device, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("create dialer error: %w", err)
 	}
 
+	con, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(dc.device.Address, dc.device.Port))
 	if err != nil {
 		return nil, fmt.Errorf("dial error: %w", err)
 	}
 
-	return con, nil
+	if !dc.device.TLS {
+		return con, nil
+	}
+
+	// tls configuration is created with configuration; create it here only for devices
+	// created without it
+	tlsConfig := dc.device.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig, err = routeros.NewTLSConfig(dc.device.TLSOptions())
+		if err != nil {
+			con.Close()
+
+			return nil, fmt.Errorf("prepare tls config error: %w", err)
+		}
+	}
+
+	// configuration is shared between devices
+	tlsConfig = tlsConfig.Clone()
+
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = dc.device.Address
+	}
+
+	tlsConn := tls.Client(con, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		con.Close()
+
+		return nil, fmt.Errorf("tls handshake error: %w", err)
+	}
+
+	return tlsConn, nil
 }
 
-// collect data for device and return number of failed collectors and
-// error if any.
-func (dc *deviceCollector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
+// collect data for device using `drcs` collectors and return error if any.
+func (dc *deviceCollector) collect(ctx context.Context, ch chan<- prometheus.Metric,
+	drcs []deviceCollectorRC,
+) error {
 	client, err := dc.connect(ctx)
 	if err != nil {
 		// clear FirmwareVersion and reload on next successful connection.
 		dc.device.FirmwareVersion.Major = 0
-		dc.errors += int64(len(dc.collectors))
+		dc.tlsCert = nil
+		dc.connected = false
+		dc.lastCollectors = nil
+		dc.errors += int64(len(drcs))
 
 		return fmt.Errorf("connect error: %w", err)
 	}
 
+	dc.connected = true
+
 	defer dc.disconnect()
+	defer client.SetRunHook(nil)
 
 	// get once version
 	if dc.device.FirmwareVersion.Major == 0 {
-		if err := dc.getVersion(client); err != nil {
-			dc.errors += int64(len(dc.collectors))
+		vctx, span := tracing.Start(ctx, "getVersion", dc.traceAttrs()...)
+		client.SetRunHook(dc.traceRunHook(vctx))
+
+		err := dc.getVersion(client)
+
+		span.SetError(err)
+		span.Finish()
+
+		if err != nil {
+			dc.errors += int64(len(drcs))
 
 			return fmt.Errorf("get version error: %w", err)
 		}
 	}
 
-	if err := dc.gatherMetrics(ctx, client, ch); err != nil {
+	if err := dc.gatherMetrics(ctx, client, ch, drcs); err != nil {
 		return fmt.Errorf("collect error: %w", err)
 	}
 
 	return nil
 }
 
+// mergeCollectorsStatus update last collectors status with `statuses`; filtered scrapes
+// run only some collectors so status of the rest is kept from previous scrapes.
+func (dc *deviceCollector) mergeCollectorsStatus(statuses []CollectorStatus) []CollectorStatus {
+	res := make([]CollectorStatus, 0, len(dc.collectors))
+
+	for _, drc := range dc.collectors {
+		match := func(s CollectorStatus) bool { return s.Name == drc.name }
+
+		if idx := slices.IndexFunc(statuses, match); idx >= 0 {
+			res = append(res, statuses[idx])
+		} else if idx := slices.IndexFunc(dc.lastCollectors, match); idx >= 0 {
+			res = append(res, dc.lastCollectors[idx])
+		}
+	}
+
+	return res
+}
+
 func (dc *deviceCollector) gatherMetrics(ctx context.Context, client *routeros.Client,
-	ch chan<- prometheus.Metric,
-) error {
+	ch chan<- prometheus.Metric, drcs []deviceCollectorRC,
+) (result error) {
 	logger := config.LogFromCtx(ctx)
 	collectErrors := 0
+	statuses := make([]CollectorStatus, 0, len(drcs))
+
+	ctx, span := tracing.Start(ctx, "gatherMetrics", dc.traceAttrs()...)
+
+	defer func() {
+		dc.lastCollectors = dc.mergeCollectorsStatus(statuses)
 
-	var result error
+		span.SetError(result)
+		span.Finish()
+	}()
 
 loop:
-	for _, drc := range dc.collectors {
+	for _, drc := range drcs {
 		llogger := logger.With("collector", drc.name)
 		cctx := metrics.NewCollectorContext(ch, &dc.device, client, drc.name, llogger, drc.featureConf)
 
 		llogger.Debug("start collect", "feature_conf", drc.featureConf)
 
-		if err := drc.collector.Collect(&cctx); err != nil {
+		sctx, cspan := tracing.Start(ctx, "collector", dc.traceAttrs(tracing.String("collector", drc.name))...)
+		client.SetRunHook(dc.traceRunHook(sctx, tracing.String("collector", drc.name)))
+
+		begin := time.Now()
+		err := drc.collector.Collect(&cctx)
+		status := CollectorStatus{Name: drc.name, Duration: time.Since(begin).Seconds()}
+
+		// do not attribute further commands to finished collector span
+		client.SetRunHook(nil)
+
+		cspan.SetError(err)
+		cspan.Finish()
+
+		if err != nil {
+			status.Error = err.Error()
+			statuses = append(statuses, status)
 			result = errors.Join(result, fmt.Errorf("collect %s error: %w", drc.name, err))
 
 			dc.errors++
@@ -224,6 +390,7 @@ loop:
 				return errors.Join(result, ErrTooManyErrors)
 			}
 		} else {
+			statuses = append(statuses, status)
 			// reset errors counter on success
 			collectErrors = 0
 		}
@@ -239,6 +406,47 @@ loop:
 	return result
 }
 
+// traceAttrs return attributes describing device for tracing spans.
+func (dc *deviceCollector) traceAttrs(extra ...tracing.Attribute) []tracing.Attribute {
+	return append([]tracing.Attribute{
+		tracing.String("dev_name", dc.device.Name),
+		tracing.String("dev_address", dc.device.Address),
+	}, extra...)
+}
+
+// traceRunHook create hook that trace each API command as child of span from ctx.
+func (dc *deviceCollector) traceRunHook(ctx context.Context, extra ...tracing.Attribute) routeros.RunHook {
+	if tracing.FromContext(ctx) == nil {
+		return nil
+	}
+
+	return func(sentence []string) func(*routeros.Reply, error) {
+		_, span := tracing.Start(ctx, "Client.Run", dc.traceAttrs(append(extra, tracing.Command(sentence))...)...)
+
+		return func(reply *routeros.Reply, err error) {
+			if reply != nil {
+				span.SetAttributes(tracing.Int("replies", len(reply.Re)))
+			}
+
+			span.SetError(err)
+			span.Finish()
+		}
+	}
+}
+
+func (dc *deviceCollector) collectTLSCertificate(ch chan<- prometheus.Metric) {
+	cert := dc.tlsCert
+	if cert == nil {
+		return
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	ch <- prometheus.MustNewConstMetric(tlsCertificateDesc, prometheus.GaugeValue,
+		float64(cert.NotAfter.Unix()), dc.device.Name, dc.device.Address,
+		cert.Subject.String(), cert.Issuer.String(), hex.EncodeToString(fingerprint[:]))
+}
+
 func (dc *deviceCollector) updateIdentity(client *routeros.Client) error {
 	reply, err := client.Run("/system/identity/print")
 	if err != nil {
@@ -268,6 +476,8 @@ func (dc *deviceCollector) getVersion(client *routeros.Client) error {
 		return fmt.Errorf("parse version %v error: %w", reply.Re[0], err)
 	}
 
+	dc.device.Board = reply.Re[0].Map["board-name"]
+
 	reply, err = client.Run("/system/clock/print")
 	if err != nil {
 		return fmt.Errorf("get clock error: %w", err)
@@ -0,0 +1,159 @@
+package collector
+
+//
+// dialer.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+)
+
+const defaultSSHPort = "22"
+
+var (
+	ErrUnsupportedProxy = errors.New("unsupported proxy")
+	ErrInvalidSSHJump   = errors.New("invalid ssh jump host; expected user@host[:port]")
+)
+
+// contextDialer open connection to device.
+type contextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// newDialer create dialer for `device`: direct, via socks5 proxy or via ssh jump host.
+func newDialer(device *config.Device, timeout time.Duration) (contextDialer, error) {
+	netDialer := &net.Dialer{Timeout: timeout}
+
+	switch {
+	case device.Proxy != "":
+		return newProxyDialer(device.Proxy, netDialer)
+	case device.SSHJump != "":
+		return newSSHDialer(device, netDialer)
+	}
+
+	return netDialer, nil
+}
+
+func newProxyDialer(proxyURL string, netDialer *net.Dialer) (contextDialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy url error: %w", err)
+	}
+
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxy, u.Scheme)
+	}
+
+	dialer, err := proxy.FromURL(u, netDialer)
+	if err != nil {
+		return nil, fmt.Errorf("create proxy dialer error: %w", err)
+	}
+
+	cdialer, ok := dialer.(contextDialer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxy, u.Scheme)
+	}
+
+	return cdialer, nil
+}
+
+// --------------------------------------------
+
+// sshDialer open connections through ssh jump host; each connection use separate ssh connection
+// that is closed with forwarded connection.
+type sshDialer struct {
+	netDialer *net.Dialer
+	config    *ssh.ClientConfig
+	address   string
+}
+
+func newSSHDialer(device *config.Device, netDialer *net.Dialer) (*sshDialer, error) {
+	user, address, ok := strings.Cut(device.SSHJump, "@")
+	if !ok || user == "" || address == "" {
+		return nil, ErrInvalidSSHJump
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultSSHPort)
+	}
+
+	// key and known hosts are loaded with configuration; load them here only for devices
+	// created without it
+	auth := device.SSHAuth
+	if auth == nil {
+		var err error
+
+		auth, err = config.LoadSSHAuth(device.SSHKey, device.SSHKnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("load ssh auth error: %w", err)
+		}
+	}
+
+	return &sshDialer{
+		netDialer: netDialer,
+		address:   address,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(auth.Signer)},
+			HostKeyCallback: auth.HostKeyCallback,
+			Timeout:         netDialer.Timeout,
+		},
+	}, nil
+}
+
+func (s *sshDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := s.netDialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return nil, fmt.Errorf("dial ssh jump host error: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	sconn, chans, reqs, err := ssh.NewClientConn(conn, s.address, s.config)
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("ssh connect error: %w", err)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	client := ssh.NewClient(sconn, chans, reqs)
+
+	fconn, err := client.DialContext(ctx, network, address)
+	if err != nil {
+		client.Close()
+
+		return nil, fmt.Errorf("dial via ssh jump host error: %w", err)
+	}
+
+	return &sshConn{fconn, client}, nil
+}
+
+// sshConn is connection forwarded by ssh client; close ssh client on Close.
+type sshConn struct {
+	net.Conn
+
+	client *ssh.Client
+}
+
+func (s *sshConn) Close() error {
+	err := s.Conn.Close()
+
+	return errors.Join(err, s.client.Close())
+}
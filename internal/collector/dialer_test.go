@@ -0,0 +1,344 @@
+package collector
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestDirectDialer(t *testing.T) {
+	target := startEchoServer(t)
+
+	dialer, err := newDialer(&config.Device{}, time.Second)
+	require.NoError(t, err)
+
+	assertEcho(t, dialer, target)
+}
+
+func TestProxyDialer(t *testing.T) {
+	target := startEchoServer(t)
+	proxyAddr := startSocks5Server(t)
+
+	dialer, err := newDialer(&config.Device{Proxy: "socks5://" + proxyAddr}, time.Second)
+	require.NoError(t, err)
+
+	assertEcho(t, dialer, target)
+
+	_, err = newDialer(&config.Device{Proxy: "http://" + proxyAddr}, time.Second)
+	require.ErrorIs(t, err, ErrUnsupportedProxy)
+}
+
+func TestSSHDialer(t *testing.T) {
+	target := startEchoServer(t)
+	dir := t.TempDir()
+
+	clientKey := filepath.Join(dir, "id_ed25519")
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(clientKey, pem.EncodeToMemory(block), 0o600))
+
+	clientSSHPub, err := ssh.NewPublicKey(clientPub)
+	require.NoError(t, err)
+
+	sshAddr, hostKey := startSSHServer(t, clientSSHPub)
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(sshAddr)}, hostKey)
+	require.NoError(t, os.WriteFile(knownHosts, []byte(line+"\n"), 0o600))
+
+	dev := &config.Device{
+		SSHJump:       "jump@" + sshAddr,
+		SSHKey:        clientKey,
+		SSHKnownHosts: knownHosts,
+	}
+
+	dialer, err := newDialer(dev, time.Second)
+	require.NoError(t, err)
+
+	assertEcho(t, dialer, target)
+
+	// key and known hosts loaded with configuration are used without reading files
+	dev.SSHAuth, err = config.LoadSSHAuth(clientKey, knownHosts)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(clientKey))
+
+	dialer, err = newDialer(dev, time.Second)
+	require.NoError(t, err)
+
+	assertEcho(t, dialer, target)
+
+	dev.SSHAuth = nil
+
+	// unknown host key
+	require.NoError(t, os.WriteFile(knownHosts, nil, 0o600))
+	require.NoError(t, os.WriteFile(clientKey, pem.EncodeToMemory(block), 0o600))
+
+	dialer, err = newDialer(dev, time.Second)
+	require.NoError(t, err)
+
+	_, err = dialer.DialContext(context.Background(), "tcp", target)
+	require.Error(t, err)
+}
+
+func assertEcho(t *testing.T, dialer contextDialer, target string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { l.Close() })
+
+	return l
+}
+
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+
+	l := listen(t)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+// startSocks5Server start minimal socks5 server that support only CONNECT without
+// authentication.
+func startSocks5Server(t *testing.T) string {
+	t.Helper()
+
+	l := listen(t)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go handleSocks5(conn)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func handleSocks5(conn net.Conn) {
+	defer conn.Close()
+
+	// greeting: ver, nmethods, methods
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		return
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, hdr[1])); err != nil {
+		return
+	}
+
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+
+	// request: ver, cmd, rsv, atyp
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return
+	}
+
+	var host string
+
+	switch req[3] {
+	case 1:
+		addr := make([]byte, 4)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return
+		}
+
+		host = net.IP(addr).String()
+	case 3:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return
+		}
+
+		name := make([]byte, size[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return
+		}
+
+		host = string(name)
+	default:
+		return
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+
+		return
+	}
+
+	defer target.Close()
+
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+
+	go func() { _, _ = io.Copy(target, conn) }()
+
+	_, _ = io.Copy(conn, target)
+}
+
+// startSSHServer start ssh server that accept only `clientKey` and support only direct-tcpip
+// channels.
+func startSSHServer(t *testing.T, clientKey ssh.PublicKey) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return &ssh.Permissions{}, nil
+			}
+
+			return nil, assert.AnError
+		},
+	}
+	cfg.AddHostKey(hostSigner)
+
+	l := listen(t)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go handleSSH(conn, cfg)
+		}
+	}()
+
+	return l.Addr().String(), hostSigner.PublicKey()
+}
+
+func handleSSH(conn net.Conn, cfg *ssh.ServerConfig) {
+	defer conn.Close()
+
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+
+	defer sconn.Close()
+
+	go ssh.DiscardRequests(reqs)
+
+	for newCh := range chans {
+		if newCh.ChannelType() != "direct-tcpip" {
+			_ = newCh.Reject(ssh.UnknownChannelType, "unsupported")
+
+			continue
+		}
+
+		var payload struct {
+			Host     string
+			Port     uint32
+			OrigHost string
+			OrigPort uint32
+		}
+
+		if err := ssh.Unmarshal(newCh.ExtraData(), &payload); err != nil {
+			_ = newCh.Reject(ssh.ConnectionFailed, err.Error())
+
+			continue
+		}
+
+		target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+		if err != nil {
+			_ = newCh.Reject(ssh.ConnectionFailed, err.Error())
+
+			continue
+		}
+
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			target.Close()
+
+			continue
+		}
+
+		go ssh.DiscardRequests(chReqs)
+
+		go func() {
+			defer ch.Close()
+			defer target.Close()
+
+			go func() { _, _ = io.Copy(target, ch) }()
+
+			_, _ = io.Copy(ch, target)
+		}()
+	}
+}
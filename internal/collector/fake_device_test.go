@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mikrotik-exporter/routeros/proto"
+)
+
+// fakeDevice is minimal RouterOS API server; reply for each command with configured
+// `!re` sentences and `!done`.
+type fakeDevice struct {
+	replies  map[string][]map[string]string
+	commands []string
+	delay    time.Duration
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+func startFakeDevice(t *testing.T, replies map[string][]map[string]string) *fakeDevice {
+	t.Helper()
+
+	fd := &fakeDevice{
+		replies:  replies,
+		listener: listen(t),
+	}
+
+	go func() {
+		for {
+			conn, err := fd.listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go fd.handle(conn)
+		}
+	}()
+
+	return fd
+}
+
+func (f *fakeDevice) address() (string, string) {
+	host, port, _ := net.SplitHostPort(f.listener.Addr().String())
+
+	return host, port
+}
+
+// setDelay set delay before each reply.
+func (f *fakeDevice) setDelay(delay time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.delay = delay
+}
+
+func (f *fakeDevice) receivedCommands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.commands...)
+}
+
+func (f *fakeDevice) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := proto.NewWriter(conn)
+
+	for {
+		words, err := readFakeSentence(r)
+		if err != nil || len(words) == 0 {
+			return
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(words, " "))
+		delay := f.delay
+		f.mu.Unlock()
+
+		time.Sleep(delay)
+
+		if words[0] == "/quit" {
+			w.BeginSentence()
+			w.WriteWord("!fatal")
+			w.WriteWord("=message=session terminated on request")
+			_ = w.EndSentence()
+
+			return
+		}
+
+		for _, re := range f.replies[words[0]] {
+			w.BeginSentence()
+			w.WriteWord("!re")
+
+			for k, v := range re {
+				w.WriteWord("=" + k + "=" + v)
+			}
+
+			if err := w.EndSentence(); err != nil {
+				return
+			}
+		}
+
+		w.BeginSentence()
+		w.WriteWord("!done")
+
+		if err := w.EndSentence(); err != nil {
+			return
+		}
+	}
+}
+
+// readFakeSentence read words until empty word; support only short (< 0x4000) words.
+func readFakeSentence(r *bufio.Reader) ([]string, error) {
+	var words []string
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		size := int(b)
+
+		if b&0xC0 == 0x80 {
+			b2, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+
+			size = int(b&^0xC0)<<8 | int(b2)
+		}
+
+		if size == 0 {
+			return words, nil
+		}
+
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		words = append(words, string(buf))
+	}
+}
@@ -0,0 +1,104 @@
+package collector
+
+//
+// filter.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"mikrotik-exporter/internal/collectors"
+	"mikrotik-exporter/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Filter limit scrape to subset of devices and collectors.
+type Filter struct {
+	// Devices is list of names of devices to scrape; all when empty.
+	Devices []string
+	// Collect is list of collectors to run; all enabled when empty.
+	Collect []string
+	// Exclude is list of collectors to skip.
+	Exclude []string
+}
+
+// IsEmpty return true when filter not limit anything.
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.Devices) == 0 && len(f.Collect) == 0 && len(f.Exclude) == 0)
+}
+
+func (f *Filter) matchDevice(dc *deviceCollector) bool {
+	return f == nil || len(f.Devices) == 0 || slices.Contains(f.Devices, dc.device.Name)
+}
+
+func (f *Filter) matchCollector(name string) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Collect) > 0 && !slices.Contains(f.Collect, name) {
+		return false
+	}
+
+	return !slices.Contains(f.Exclude, name)
+}
+
+// collectors return device collectors accepted by filter.
+func (f *Filter) collectors(drcs []deviceCollectorRC) []deviceCollectorRC {
+	if f == nil || (len(f.Collect) == 0 && len(f.Exclude) == 0) {
+		return drcs
+	}
+
+	res := make([]deviceCollectorRC, 0, len(drcs))
+
+	for _, drc := range drcs {
+		if f.matchCollector(drc.name) {
+			res = append(res, drc)
+		}
+	}
+
+	return res
+}
+
+func (f *Filter) validate(devices []*deviceCollector) error {
+	var errs error
+
+	for _, name := range f.Devices {
+		if !slices.ContainsFunc(devices, func(dc *deviceCollector) bool { return dc.device.Name == name }) {
+			errs = errors.Join(errs, fmt.Errorf("%w: %s", config.ErrUnknownDevice, name))
+		}
+	}
+
+	available := collectors.AvailableCollectorsNames()
+
+	for _, name := range slices.Concat(f.Collect, f.Exclude) {
+		if !slices.Contains(available, name) {
+			errs = errors.Join(errs, fmt.Errorf("%w: %s", ErrUnknownCollector, name))
+		}
+	}
+
+	return errs
+}
+
+// --------------------------------------------
+
+// filteredCollector scrape only devices and collectors selected by filter.
+type filteredCollector struct {
+	parent *mikrotikCollector
+	filter *Filter
+}
+
+// Describe implements the prometheus.Collector interface.
+func (f *filteredCollector) Describe(ch chan<- *prometheus.Desc) {
+	f.parent.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface.
+func (f *filteredCollector) Collect(ch chan<- prometheus.Metric) {
+	f.parent.collect(ch, f.filter)
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"testing"
+
+	"mikrotik-exporter/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+			{Name: "dev2", Address: "127.0.0.1", Port: "1", User: "user", Password: "pass", Timeout: 1},
+		},
+		Features: config.Features{
+			"resource": config.NewFeatureConf(),
+			"health":   config.NewFeatureConf(),
+		},
+	}
+
+	coll := NewCollector(cfg)
+
+	_, err := coll.WithFilter(&Filter{Devices: []string{"unknown"}})
+	require.ErrorIs(t, err, config.ErrUnknownDevice)
+
+	_, err = coll.WithFilter(&Filter{Exclude: []string{"unknown"}})
+	require.ErrorIs(t, err, ErrUnknownCollector)
+
+	fcoll, err := coll.WithFilter(&Filter{Devices: []string{"dev1"}, Exclude: []string{"health"}})
+	require.NoError(t, err)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(fcoll))
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "dev_name" {
+					assert.Equal(t, "dev1", l.GetValue())
+				}
+			}
+		}
+	}
+
+	assert.NotContains(t, fd.receivedCommands(), "/system/health/print")
+
+	status := coll.DevicesStatus()
+	require.Len(t, status[0].Collectors, 1)
+	assert.Equal(t, "resource", status[0].Collectors[0].Name)
+	assert.Equal(t, StateUnknown, status[1].State)
+}
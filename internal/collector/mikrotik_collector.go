@@ -10,14 +10,19 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"mikrotik-exporter/internal/collectors"
 	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/tracing"
 
 	"github.com/coreos/go-systemd/v22/daemon"
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
 )
 
 // --------------------------------------------
@@ -35,23 +40,78 @@ var (
 		[]string{"dev_name", "dev_address"},
 		nil,
 	)
+	shardInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(config.Namespace, "shard", "info"),
+		"mikrotik_exporter: shard handled by this exporter instance",
+		[]string{"shard_index", "shard_count"},
+		nil,
+	)
+	shardDevicesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(config.Namespace, "shard", "devices"),
+		"mikrotik_exporter: number of devices handled by this exporter instance",
+		nil,
+		nil,
+	)
 )
 
 // --------------------------------------------
 
+// Collector collect metrics from all configured devices.
+type Collector interface {
+	prometheus.Collector
+
+	// DevicesStatus return status of all devices, including discovered by srv records.
+	DevicesStatus() []DeviceStatus
+	// Shutdown stop accepting new scrapes, wait for in-flight scrapes until ctx is done
+	// and close all connections to devices.
+	Shutdown(ctx context.Context) error
+	// WithFilter return collector that scrape only devices and collectors selected
+	// by `filter`.
+	WithFilter(filter *Filter) (prometheus.Collector, error)
+}
+
 type mikrotikCollector struct {
 	devices    []*deviceCollector
 	collectors []collectors.RouterOSCollector
+
+	// srvDevices keep devices discovered by srv record on last scrape.
+	srvDevices map[*deviceCollector][]*deviceCollector
+	srvMu      sync.Mutex
+
+	// scrapes coalesce concurrent scrapes of the same device with the same collectors.
+	scrapes singleflight.Group
+
+	// sharding limit devices handled by this instance; nil when disabled.
+	sharding *config.Sharding
+
+	// ctx is canceled when shutdown timed out; abort in-flight scrapes.
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelFunc
+	// inflight track running scrapes; closing is set on shutdown.
+	inflight   sync.WaitGroup
+	closing    bool
+	shutdownMu sync.Mutex
+
+	// deviceInfoDesc describe metric with additional devices labels.
+	deviceInfoDesc *prometheus.Desc
+	labelNames     []string
 }
 
 // NewCollector creates a collector instance.
-func NewCollector(cfg *config.Config) prometheus.Collector {
+func NewCollector(cfg *config.Config) Collector {
 	slog.Info("setting up collector for devices", "numDevices", len(cfg.Devices))
 
 	dcs := make([]*deviceCollector, 0, len(cfg.Devices))
 	collectorInstances := createCollectors(cfg)
 
 	for _, dev := range cfg.Devices {
+		// srv-defined devices are sharded by discovered targets
+		if dev.Srv == nil && !cfg.Sharding.Owns(dev.Name) {
+			slog.Debug("device skipped by sharding", "device", dev.Name)
+
+			continue
+		}
+
 		feat := cfg.DeviceFeatures(dev.Name)
 		featNames := feat.FeatureNames()
 		dcols := collectorInstances.get(featNames, feat)
@@ -62,9 +122,22 @@ func NewCollector(cfg *config.Config) prometheus.Collector {
 	}
 
 	colls := collectorInstances.instances()
+	labelNames := cfg.DeviceLabelNames()
+	ctx, cancel := context.WithCancel(context.Background())
 	c := &mikrotikCollector{
+		ctx:        ctx,
+		cancel:     cancel,
 		devices:    dcs,
 		collectors: colls,
+		srvDevices: make(map[*deviceCollector][]*deviceCollector),
+		sharding:   cfg.Sharding,
+		labelNames: labelNames,
+		deviceInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(config.Namespace, "device", "info"),
+			"mikrotik_exporter: additional labels defined for device",
+			append([]string{"dev_name", "dev_address"}, labelNames...),
+			nil,
+		),
 	}
 
 	return c
@@ -75,6 +148,10 @@ func (c *mikrotikCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- scrapeDeviceDurationDesc
 	ch <- scrapeDeviceSuccessDesc
 	ch <- scrapeCollectorErrorsDesc
+	ch <- tlsCertificateDesc
+	ch <- c.deviceInfoDesc
+	ch <- shardInfoDesc
+	ch <- shardDevicesDesc
 
 	for _, co := range c.collectors {
 		co.Describe(ch)
@@ -83,18 +160,56 @@ func (c *mikrotikCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements the prometheus.Collector interface.
 func (c *mikrotikCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(ch, nil)
+}
+
+// WithFilter implements Collector interface.
+func (c *mikrotikCollector) WithFilter(filter *Filter) (prometheus.Collector, error) {
+	if filter.IsEmpty() {
+		return c, nil
+	}
+
+	if err := filter.validate(c.devices); err != nil {
+		return nil, err
+	}
+
+	return &filteredCollector{c, filter}, nil
+}
+
+func (c *mikrotikCollector) collect(ch chan<- prometheus.Metric, filter *Filter) {
+	c.shutdownMu.Lock()
+	if c.closing {
+		c.shutdownMu.Unlock()
+		slog.Debug("exporter is shutting down; skipping collect")
+
+		return
+	}
+
+	c.inflight.Add(1)
+	c.shutdownMu.Unlock()
+
+	defer c.inflight.Done()
+
 	_, _ = daemon.SdNotify(false, "STATUS=collecting")
 
 	wg := sync.WaitGroup{}
 	realDevices := make([]*deviceCollector, 0, len(c.devices))
 
 	for _, dc := range c.devices {
+		if !filter.matchDevice(dc) {
+			continue
+		}
+
 		if dc.isSrv {
-			if devs, err := c.devicesFromSrv(dc); err == nil {
+			devs, err := c.devicesFromSrv(dc)
+			if err == nil {
 				realDevices = append(realDevices, devs...)
 			} else {
 				slog.Error("resolve srv error", "src_record", dc.device.Srv.Record, "err", err)
 			}
+
+			dc.updateStatus(time.Now(), 0, err)
+			c.setSrvDevices(dc, devs)
 		} else {
 			realDevices = append(realDevices, dc)
 		}
@@ -102,23 +217,73 @@ func (c *mikrotikCollector) Collect(ch chan<- prometheus.Metric) {
 
 	wg.Add(len(realDevices))
 
-	ctx := context.Background()
+	ctx := c.ctx
 
 	for _, dev := range realDevices {
 		go func(d *deviceCollector) {
-			c.collectFromDevice(ctx, d, ch)
+			c.collectFromDevice(ctx, d, ch, filter)
 			wg.Done()
 		}(dev)
 	}
 
 	wg.Wait()
 
+	c.collectShardInfo(ch)
+
 	_, _ = daemon.SdNotify(false, "STATUS=waiting")
 }
 
 func (c *mikrotikCollector) collectFromDevice(ctx context.Context,
-	devcollector *deviceCollector, ch chan<- prometheus.Metric,
+	devcollector *deviceCollector, ch chan<- prometheus.Metric, filter *Filter,
 ) {
+	drcs := filter.collectors(devcollector.collectors)
+
+	names := make([]string, 0, len(drcs))
+	for _, drc := range drcs {
+		names = append(names, drc.name)
+	}
+
+	// device name may change (srv devices take identity on connect); pointer is stable
+	key := fmt.Sprintf("%p\x00%s", devcollector, strings.Join(names, ","))
+
+	res, _, _ := c.scrapes.Do(key, func() (any, error) {
+		return c.scrapeDevice(ctx, devcollector, drcs), nil
+	})
+
+	for _, m := range res.([]prometheus.Metric) { //nolint:forcetypeassert
+		ch <- m
+	}
+}
+
+// scrapeDevice run `drcs` collectors on device and return all collected metrics.
+func (c *mikrotikCollector) scrapeDevice(ctx context.Context,
+	devcollector *deviceCollector, drcs []deviceCollectorRC,
+) []prometheus.Metric {
+	ch := make(chan prometheus.Metric)
+	done := make(chan []prometheus.Metric)
+
+	go func() {
+		var res []prometheus.Metric
+		for m := range ch {
+			res = append(res, m)
+		}
+
+		done <- res
+	}()
+
+	c.collectDeviceMetrics(ctx, devcollector, drcs, ch)
+	close(ch)
+
+	return <-done
+}
+
+func (c *mikrotikCollector) collectDeviceMetrics(ctx context.Context,
+	devcollector *deviceCollector, drcs []deviceCollectorRC, ch chan<- prometheus.Metric,
+) {
+	// connection and device state are shared by all scrapes of the device
+	devcollector.collectMu.Lock()
+	defer devcollector.collectMu.Unlock()
+
 	address, name := devcollector.device.Address, devcollector.device.Name
 
 	logger := slog.Default().With("device", name)
@@ -138,10 +303,17 @@ func (c *mikrotikCollector) collectFromDevice(ctx context.Context,
 		}
 	}()
 
+	ctx, span := tracing.Start(ctx, "collectFromDevice", devcollector.traceAttrs()...)
+
 	begin := time.Now()
-	err := devcollector.collect(ctx, ch)
+	err := devcollector.collect(ctx, ch, drcs)
 	duration := time.Since(begin)
 
+	span.SetError(err)
+	span.Finish()
+
+	devcollector.updateStatus(begin, duration, err)
+
 	if err != nil {
 		logger.ErrorContext(ctx, fmt.Sprintf("collector failed after %fs", duration.Seconds()), "err", err)
 		ch <- prometheus.MustNewConstMetric(scrapeDeviceSuccessDesc, prometheus.GaugeValue, 0.0, name, address)
@@ -155,6 +327,17 @@ func (c *mikrotikCollector) collectFromDevice(ctx context.Context,
 
 	ch <- prometheus.MustNewConstMetric(scrapeDeviceDurationDesc, prometheus.GaugeValue, duration.Seconds(),
 		name, address)
+
+	devcollector.collectTLSCertificate(ch)
+
+	labels := make([]string, 0, len(c.labelNames)+2) //nolint:mnd
+	labels = append(labels, name, address)
+
+	for _, l := range c.labelNames {
+		labels = append(labels, devcollector.device.Labels[l])
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.deviceInfoDesc, prometheus.GaugeValue, 1.0, labels...)
 }
 
 func (c *mikrotikCollector) devicesFromSrv(devCol *deviceCollector) ([]*deviceCollector, error) {
@@ -165,19 +348,142 @@ func (c *mikrotikCollector) devicesFromSrv(devCol *deviceCollector) ([]*deviceCo
 		return nil, fmt.Errorf("dns query for %s error: %w", dev.Srv.Record, err)
 	}
 
+	c.srvMu.Lock()
+	prevDevices := c.srvDevices[devCol]
+	c.srvMu.Unlock()
+
 	realDevices := make([]*deviceCollector, 0, len(r))
 
 	for _, target := range r {
-		d := config.Device{
-			Name:     target,
-			Address:  target,
-			User:     dev.User,
-			Password: dev.Password,
-			Srv:      dev.Srv,
+		if !c.sharding.Owns(target) {
+			continue
 		}
 
-		realDevices = append(realDevices, newDeviceCollector(d, devCol.collectors))
+		// reuse previously discovered device to keep its state; match by srv target as device name
+		// is replaced by identity after connect.
+		idx := slices.IndexFunc(prevDevices, func(dc *deviceCollector) bool { return dc.srvTarget == target })
+		if idx >= 0 {
+			realDevices = append(realDevices, prevDevices[idx])
+
+			continue
+		}
+
+		// discovered devices inherit connection settings from srv entry
+		d := dev
+		d.Name = target
+		d.Address = target
+
+		ndc := newDeviceCollector(d, devCol.collectors)
+		ndc.srvTarget = target
+
+		realDevices = append(realDevices, ndc)
 	}
 
 	return realDevices, nil
 }
+
+func (c *mikrotikCollector) setSrvDevices(srvDevice *deviceCollector, devices []*deviceCollector) {
+	c.srvMu.Lock()
+	prevDevices := c.srvDevices[srvDevice]
+	c.srvDevices[srvDevice] = devices
+	c.srvMu.Unlock()
+
+	// devices no longer discovered are dropped; remove its state from collectors
+	for _, dc := range prevDevices {
+		if !slices.Contains(devices, dc) {
+			dc.removeState()
+		}
+	}
+}
+
+// DevicesStatus implements Collector interface.
+func (c *mikrotikCollector) DevicesStatus() []DeviceStatus {
+	c.srvMu.Lock()
+	defer c.srvMu.Unlock()
+
+	res := make([]DeviceStatus, 0, len(c.devices))
+
+	for _, dc := range c.devices {
+		if !dc.isSrv {
+			res = append(res, dc.getStatus())
+
+			continue
+		}
+
+		// show srv entry only when resolve failed
+		if status := dc.getStatus(); status.LastError != "" {
+			res = append(res, status)
+		}
+
+		for _, sdc := range c.srvDevices[dc] {
+			res = append(res, sdc.getStatus())
+		}
+	}
+
+	return res
+}
+
+// collectShardInfo export shard of this instance and number of handled devices.
+func (c *mikrotikCollector) collectShardInfo(ch chan<- prometheus.Metric) {
+	index, count := 0, 1
+	if c.sharding != nil {
+		index, count = c.sharding.Index, c.sharding.Count
+	}
+
+	ch <- prometheus.MustNewConstMetric(shardInfoDesc, prometheus.GaugeValue, 1.0,
+		strconv.Itoa(index), strconv.Itoa(count))
+
+	c.srvMu.Lock()
+	defer c.srvMu.Unlock()
+
+	devices := 0
+
+	for _, dc := range c.devices {
+		if dc.isSrv {
+			devices += len(c.srvDevices[dc])
+		} else {
+			devices++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(shardDevicesDesc, prometheus.GaugeValue, float64(devices))
+}
+
+// Shutdown implements Collector interface.
+func (c *mikrotikCollector) Shutdown(ctx context.Context) error {
+	c.shutdownMu.Lock()
+	c.closing = true
+	c.shutdownMu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+
+	var err error
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("wait for scrapes error: %w", ctx.Err())
+		// abort running scrapes
+		c.cancel()
+	}
+
+	c.srvMu.Lock()
+	defer c.srvMu.Unlock()
+
+	for _, dc := range c.devices {
+		dc.close()
+
+		for _, sdc := range c.srvDevices[dc] {
+			sdc.close()
+		}
+	}
+
+	c.cancel()
+
+	return err
+}
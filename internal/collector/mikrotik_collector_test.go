@@ -0,0 +1,319 @@
+package collector
+
+import (
+	"context"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentScrapes(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print":  {{"time-zone-name": "Europe/Warsaw"}},
+		"/system/health/print": {{"name": "temperature", "value": "40", "type": "C"}},
+	})
+	fd.setDelay(100 * time.Millisecond)
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+		},
+		Features: config.Features{"health": config.NewFeatureConf()},
+	}
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(NewCollector(cfg)))
+
+	var wg sync.WaitGroup
+
+	results := make([]int, 3)
+
+	for i := range results {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			mfs, err := reg.Gather()
+			assert.NoError(t, err)
+
+			results[i] = len(mfs)
+		}()
+	}
+
+	wg.Wait()
+
+	// all requests get full result
+	assert.Positive(t, results[0])
+	assert.Equal(t, results[0], results[1])
+	assert.Equal(t, results[0], results[2])
+
+	// but device is scraped once
+	commands := fd.receivedCommands()
+	assert.Len(t, slices.DeleteFunc(commands, func(c string) bool { return c != "/system/health/print" }), 1)
+}
+
+func TestSharding(t *testing.T) {
+	cfg := &config.Config{
+		Features: config.Features{"resource": config.NewFeatureConf()},
+		Sharding: &config.Sharding{Index: 1, Count: 2},
+	}
+
+	owned := 0
+
+	for i := range 10 {
+		name := "dev" + strconv.Itoa(i)
+		cfg.Devices = append(cfg.Devices, config.Device{
+			Name: name, Address: "127.0.0.1", Port: "1", User: "user", Password: "pass", Timeout: 1,
+		})
+
+		if config.ShardFor(name, 2) == 1 {
+			owned++
+		}
+	}
+
+	coll := NewCollector(cfg)
+
+	status := coll.DevicesStatus()
+	require.Len(t, status, owned)
+
+	for _, s := range status {
+		assert.Equal(t, 1, config.ShardFor(s.Name, 2))
+	}
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(coll))
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+
+	for _, mf := range mfs {
+		if name := mf.GetName(); name == "mikrotik_shard_devices" || name == "mikrotik_shard_info" {
+			require.Len(t, mf.GetMetric(), 1)
+
+			m := mf.GetMetric()[0]
+			values[name] = m.GetGauge().GetValue()
+
+			for _, lp := range m.GetLabel() {
+				values[name+"/"+lp.GetName()+"="+lp.GetValue()] = 1
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"mikrotik_shard_devices":            float64(owned),
+		"mikrotik_shard_info":               1,
+		"mikrotik_shard_info/shard_count=2": 1,
+		"mikrotik_shard_info/shard_index=1": 1,
+	}, values)
+}
+
+func TestShutdown(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+	fd.setDelay(50 * time.Millisecond)
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+		},
+		Features: config.Features{"resource": config.NewFeatureConf()},
+	}
+
+	coll := NewCollector(cfg)
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(coll))
+
+	gathered := make(chan int)
+
+	go func() {
+		mfs, err := reg.Gather()
+		assert.NoError(t, err)
+
+		gathered <- len(mfs)
+	}()
+
+	// wait for scrape to start
+	require.Eventually(t, func() bool { return len(fd.receivedCommands()) > 0 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, coll.Shutdown(ctx))
+
+	// in-flight scrape finished
+	assert.Positive(t, <-gathered)
+	assert.Equal(t, "/quit", fd.receivedCommands()[len(fd.receivedCommands())-1])
+
+	// new scrapes are rejected
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	assert.Empty(t, mfs)
+}
+
+// newSrvTestCollector create collector with one srv-defined device; device identity differ from
+// srv target.
+func newSrvTestCollector(t *testing.T) (*mikrotikCollector, *prometheus.Registry) {
+	t.Helper()
+
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/identity/print": {{"name": "router1"}},
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+
+	host, port := fd.address()
+	dnsAddr := startFakeDNS(t, host+".")
+
+	dnsHost, dnsPort, _ := net.SplitHostPort(dnsAddr)
+	dnsPortN, _ := strconv.Atoi(dnsPort)
+
+	cfg := &config.Config{
+		Devices: []config.Device{{
+			Name: "srv", Port: port, User: "user", Password: "pass",
+			Srv: &config.SrvRecord{
+				DNS:    &config.DNSServer{Address: dnsHost, Port: dnsPortN},
+				Record: "_mikrotik._tcp.example.com",
+			},
+		}},
+		Features: config.Features{"resource": config.NewFeatureConf()},
+	}
+
+	coll, ok := NewCollector(cfg).(*mikrotikCollector)
+	require.True(t, ok)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(coll))
+
+	return coll, reg
+}
+
+func TestSrvDevicesReused(t *testing.T) {
+	coll, reg := newSrvTestCollector(t)
+
+	_, err := reg.Gather()
+	require.NoError(t, err)
+
+	coll.srvMu.Lock()
+	first := slices.Concat(slices.Collect(maps.Values(coll.srvDevices))...)
+	coll.srvMu.Unlock()
+
+	require.Len(t, first, 1)
+	// identity differ from srv target
+	assert.Equal(t, "router1", first[0].device.Name)
+
+	_, err = reg.Gather()
+	require.NoError(t, err)
+
+	coll.srvMu.Lock()
+	second := slices.Concat(slices.Collect(maps.Values(coll.srvDevices))...)
+	coll.srvMu.Unlock()
+
+	require.Len(t, second, 1)
+	assert.Same(t, first[0], second[0])
+
+	status := coll.DevicesStatus()
+	require.Len(t, status, 1)
+	assert.Equal(t, "router1", status[0].Name)
+	assert.NotEmpty(t, status[0].Collectors)
+}
+
+func TestSrvDevicesParallelScrapes(t *testing.T) {
+	coll, reg := newSrvTestCollector(t)
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			_, err := reg.Gather()
+			assert.NoError(t, err)
+		})
+	}
+
+	wg.Wait()
+
+	status := coll.DevicesStatus()
+	require.Len(t, status, 1)
+	assert.Equal(t, "router1", status[0].Name)
+}
+
+type stateCollector struct {
+	removed []*config.Device
+}
+
+func (s *stateCollector) Describe(chan<- *prometheus.Desc)        {}
+func (s *stateCollector) Collect(*metrics.CollectorContext) error { return nil }
+func (s *stateCollector) RemoveDevice(device *config.Device)      { s.removed = append(s.removed, device) }
+
+func TestSrvDevicesRemoveState(t *testing.T) {
+	sc := &stateCollector{}
+	drcs := []deviceCollectorRC{{sc, "state", config.NewFeatureConf()}}
+
+	srv := newDeviceCollector(config.Device{Name: "srv", Srv: &config.SrvRecord{}}, drcs)
+	dev1 := newDeviceCollector(config.Device{Name: "dev1"}, drcs)
+	dev2 := newDeviceCollector(config.Device{Name: "dev2"}, drcs)
+
+	coll := &mikrotikCollector{srvDevices: make(map[*deviceCollector][]*deviceCollector)}
+
+	coll.setSrvDevices(srv, []*deviceCollector{dev1, dev2})
+	assert.Empty(t, sc.removed)
+
+	coll.setSrvDevices(srv, []*deviceCollector{dev2})
+	assert.Equal(t, []*config.Device{&dev1.device}, sc.removed)
+}
+
+// startFakeDNS start dns server that answer each query with one SRV record pointing to `target`.
+func startFakeDNS(t *testing.T, target string) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Answer = append(m.Answer, &dns.SRV{
+				Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 60},
+				Target: target,
+				Port:   8728,
+			})
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = srv.ActivateAndServe() }()
+
+	t.Cleanup(func() { _ = srv.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
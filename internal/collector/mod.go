@@ -22,6 +22,8 @@ var (
 	ErrNoServersDefined = errors.New("no servers defined")
 	ErrInvalidResponse  = errors.New("invalid response")
 	ErrTooManyErrors    = errors.New("too many errors")
+	ErrUnknownCollector = errors.New("unknown collector")
+	ErrCollectorPanic   = errors.New("collector panic")
 )
 
 func resolveServices(srvDNS *config.DNSServer, record string) ([]string, error) {
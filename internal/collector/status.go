@@ -0,0 +1,108 @@
+package collector
+
+//
+// status.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"fmt"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+)
+
+// Connection states reported in DeviceStatus.
+const (
+	StateUnknown      = "unknown"
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected"
+	StateFailed       = "failed"
+)
+
+type (
+	// CollectorStatus is result of last run of collector for device.
+	CollectorStatus struct {
+		Name     string  `json:"name"`
+		Error    string  `json:"error,omitempty"`
+		Duration float64 `json:"duration_seconds"`
+	}
+
+	// DeviceStatus describe device and result of last scrape.
+	DeviceStatus struct {
+		LastScrape      time.Time         `json:"last_scrape,omitzero"`
+		Name            string            `json:"name"`
+		Address         string            `json:"address"`
+		Port            string            `json:"port"`
+		Srv             string            `json:"srv,omitempty"`
+		Profile         string            `json:"profile,omitempty"`
+		FirmwareVersion string            `json:"firmware_version,omitempty"`
+		Architecture    string            `json:"architecture,omitempty"`
+		Board           string            `json:"board,omitempty"`
+		Timezone        string            `json:"timezone,omitempty"`
+		State           string            `json:"state"`
+		LastError       string            `json:"last_error,omitempty"`
+		Labels          map[string]string `json:"labels,omitempty"`
+		EnabledFeatures []string          `json:"enabled_collectors"`
+		Collectors      []CollectorStatus `json:"collectors,omitempty"`
+		LastDuration    float64           `json:"last_duration_seconds"`
+		Errors          int64             `json:"errors_total"`
+	}
+)
+
+// newDeviceStatus create initial status for device.
+func newDeviceStatus(device *config.Device, collectors []deviceCollectorRC) DeviceStatus {
+	features := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		features = append(features, c.name)
+	}
+
+	var srv string
+	if device.Srv != nil {
+		srv = device.Srv.Record
+	}
+
+	return DeviceStatus{
+		Name:            device.Name,
+		Address:         device.Address,
+		Port:            device.Port,
+		Srv:             srv,
+		Profile:         device.Profile,
+		Labels:          device.Labels,
+		State:           StateUnknown,
+		EnabledFeatures: features,
+	}
+}
+
+// update status after scrape.
+func (s *DeviceStatus) update(dc *deviceCollector, begin time.Time, duration time.Duration, err error) {
+	fv := &dc.device.FirmwareVersion
+
+	s.Name = dc.device.Name
+	s.LastScrape = begin
+	s.LastDuration = duration.Seconds()
+	s.Errors = dc.errors
+	s.Collectors = dc.lastCollectors
+	s.LastError = ""
+
+	if err != nil {
+		s.LastError = err.Error()
+	}
+
+	if fv.Major > 0 {
+		s.FirmwareVersion = fmt.Sprintf("%d.%d.%d", fv.Major, fv.Minor, fv.Patch)
+		s.Architecture = fv.Architecture
+		s.Board = dc.device.Board
+		s.Timezone = dc.device.Timezone
+	}
+
+	switch {
+	case !dc.connected:
+		s.State = StateFailed
+	case dc.cl != nil:
+		s.State = StateConnected
+	default:
+		s.State = StateDisconnected
+	}
+}
@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"testing"
+
+	"mikrotik-exporter/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevicesStatus(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+			{Name: "dev2", Address: "127.0.0.1", Port: "1", User: "user", Password: "pass", Timeout: 1},
+		},
+		Features: config.Features{"resource": config.NewFeatureConf()},
+	}
+
+	coll := NewCollector(cfg)
+
+	status := coll.DevicesStatus()
+	require.Len(t, status, 2)
+	assert.Equal(t, StateUnknown, status[0].State)
+	assert.Equal(t, []string{"resource"}, status[0].EnabledFeatures)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(coll))
+
+	_, err := reg.Gather()
+	require.NoError(t, err)
+
+	status = coll.DevicesStatus()
+	require.Len(t, status, 2)
+
+	assert.Equal(t, "dev1", status[0].Name)
+	assert.Equal(t, StateConnected, status[0].State)
+	assert.Equal(t, "7.15.2", status[0].FirmwareVersion)
+	assert.Equal(t, "RB5009", status[0].Board)
+	assert.Empty(t, status[0].LastError)
+	require.Len(t, status[0].Collectors, 1)
+	assert.Equal(t, "resource", status[0].Collectors[0].Name)
+	assert.False(t, status[0].LastScrape.IsZero())
+
+	assert.Equal(t, "dev2", status[1].Name)
+	assert.Equal(t, StateFailed, status[1].State)
+	assert.NotEmpty(t, status[1].LastError)
+	assert.Positive(t, status[1].Errors)
+}
+
+func TestDevicesStatusFilteredScrape(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print":  {{"time-zone-name": "Europe/Warsaw"}},
+		"/system/health/print": {{"name": "temperature", "value": "40", "type": "C"}},
+	})
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+		},
+		Features: config.Features{
+			"resource": config.NewFeatureConf(),
+			"health":   config.NewFeatureConf(),
+		},
+	}
+
+	coll := NewCollector(cfg)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(coll))
+
+	_, err := reg.Gather()
+	require.NoError(t, err)
+
+	status := coll.DevicesStatus()
+	require.Len(t, status, 1)
+	require.Len(t, status[0].Collectors, 2)
+
+	before := status[0].Collectors
+
+	fcoll, err := coll.WithFilter(&Filter{Collect: []string{"health"}})
+	require.NoError(t, err)
+
+	freg := prometheus.NewRegistry()
+	require.NoError(t, freg.Register(fcoll))
+
+	_, err = freg.Gather()
+	require.NoError(t, err)
+
+	// status of collectors not run in filtered scrape is kept
+	status = coll.DevicesStatus()
+	require.Len(t, status, 1)
+	require.Len(t, status[0].Collectors, 2)
+
+	for i, cs := range status[0].Collectors {
+		assert.Equal(t, before[i].Name, cs.Name)
+
+		if cs.Name == "resource" {
+			assert.Equal(t, before[i], cs)
+		}
+	}
+}
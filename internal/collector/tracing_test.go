@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"mikrotik-exporter/internal/collectors"
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryExporter keep exported spans in memory.
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []*tracing.Span
+}
+
+func (m *memoryExporter) Export(_ context.Context, spans []*tracing.Span) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.spans = append(m.spans, spans...)
+
+	return nil
+}
+
+func (m *memoryExporter) Shutdown(_ context.Context) error {
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/resource/print": {{
+			"version": "7.15.2 (stable)", "architecture-name": "arm64", "board-name": "RB5009",
+			"uptime": "1d2h", "cpu-load": "3", "free-memory": "1000", "total-memory": "2000",
+		}},
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+
+	host, port := fd.address()
+	cfg := &config.Config{
+		Devices: []config.Device{
+			{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"},
+		},
+		Features: config.Features{"resource": config.NewFeatureConf()},
+	}
+
+	exporter := &memoryExporter{}
+	tracer := tracing.Setup(exporter)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(NewCollector(cfg)))
+
+	_, err := reg.Gather()
+	require.NoError(t, err)
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	names := make(map[string]int)
+	// command -> collectors
+	commands := make(map[string][]string)
+
+	for _, s := range exporter.spans {
+		names[s.Name]++
+
+		attrs := make(map[string]any)
+		for _, a := range s.Attributes {
+			attrs[a.Key] = a.Value
+		}
+
+		assert.Equal(t, "dev1", attrs["dev_name"], s.Name)
+
+		if cmd, ok := attrs["command"].(string); ok {
+			collector, _ := attrs["collector"].(string)
+			commands[cmd] = append(commands[cmd], collector)
+		}
+	}
+
+	assert.Equal(t, 1, names["collectFromDevice"])
+	assert.Equal(t, 1, names["connect"])
+	assert.Equal(t, 1, names["getVersion"])
+	assert.Equal(t, 1, names["gatherMetrics"])
+	assert.Equal(t, 1, names["collector"])
+	assert.Positive(t, names["Client.Run"])
+
+	assert.Contains(t, commands, "/login =name=user =password=***")
+	assert.Equal(t, []string{""}, commands["/system/resource/print"])
+
+	resourceCollector := false
+
+	for cmd, colls := range commands {
+		if strings.HasPrefix(cmd, "/system/resource/print =.proplist=") {
+			resourceCollector = slices.Equal([]string{"resource"}, colls)
+		}
+	}
+
+	assert.True(t, resourceCollector)
+	assert.Contains(t, commands, "/system/clock/print")
+}
+
+func TestGatherMetricsResetRunHook(t *testing.T) {
+	fd := startFakeDevice(t, map[string][]map[string]string{
+		"/system/clock/print": {{"time-zone-name": "Europe/Warsaw"}},
+	})
+
+	host, port := fd.address()
+	drcs := []deviceCollectorRC{{collectors.InstanateCollector("resource"), "resource", config.NewFeatureConf()}}
+	dc := newDeviceCollector(config.Device{Name: "dev1", Address: host, Port: port, User: "user", Password: "pass"}, drcs)
+
+	exporter := &memoryExporter{}
+	tracer := tracing.Setup(exporter)
+
+	client, err := dc.connect(context.Background())
+	require.NoError(t, err)
+
+	t.Cleanup(client.Close)
+
+	ch := make(chan prometheus.Metric, 100)
+	_ = dc.gatherMetrics(context.Background(), client, ch, drcs)
+
+	// command run after collectors should not be traced
+	_, err = client.Run("/system/clock/print")
+	require.NoError(t, err)
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	for _, s := range exporter.spans {
+		for _, a := range s.Attributes {
+			if a.Key == "command" {
+				assert.NotEqual(t, "/system/clock/print", a.Value, "command traced after collectors")
+			}
+		}
+	}
+}
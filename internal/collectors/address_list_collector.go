@@ -0,0 +1,439 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("address_list", newAddressListCollector,
+		"retrieves firewall address-list entries count and connection tracking breakdown")
+}
+
+// defaultMaxConnections is default limit of tracked connections loaded to count connections
+// by address-list.
+const defaultMaxConnections = 10000
+
+var ErrTooManyConnections = errors.New("too many tracked connections")
+
+type addressListCollector struct {
+	entries      metrics.PropertyMetric
+	withTimeout  metrics.PropertyMetric
+	connProtocol metrics.PropertyMetric
+	connTCPState metrics.PropertyMetric
+	connList     metrics.PropertyMetric
+	// protocols is default list of protocols for connections breakdown for each topic.
+	protocols map[string][]string
+	tcpStates []string
+	topics    []string
+}
+
+func newAddressListCollector() RouterOSCollector {
+	return &addressListCollector{
+		entries: metrics.NewPropertyRetMetric("address_list", "entries", "ip_version", "list", "type").
+			WithHelp("number of entries in address-list").
+			Build(),
+		withTimeout: metrics.NewPropertyRetMetric("address_list", "entries_with_timeout", "ip_version", "list").
+			WithHelp("number of entries with timeout in address-list").
+			Build(),
+		connProtocol: metrics.NewPropertyRetMetric("conntrack", "connections_protocol", "ip_version", "protocol").
+			WithHelp("number of tracked connections by protocol").
+			Build(),
+		connTCPState: metrics.NewPropertyRetMetric("conntrack", "connections_tcp_state", "ip_version", "state").
+			WithHelp("number of tracked tcp connections by state").
+			Build(),
+		connList: metrics.NewPropertyRetMetric("conntrack", "connections_address_list",
+			"ip_version", "list", "direction").
+			WithHelp("number of tracked connections with source or destination in address-list").
+			Build(),
+
+		protocols: map[string][]string{
+			"ip":   {"tcp", "udp", "icmp", "gre", "ipsec-esp", "ipsec-ah"},
+			"ipv6": {"tcp", "udp", "icmpv6", "gre", "ipsec-esp", "ipsec-ah"},
+		},
+		tcpStates: []string{
+			"syn-sent", "syn-received", "established", "fin-wait", "close-wait",
+			"last-ack", "time-wait", "close",
+		},
+		topics: []string{"ip", "ipv6"},
+	}
+}
+
+func (c *addressListCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.entries.Describe(ch)
+	c.withTimeout.Describe(ch)
+	c.connProtocol.Describe(ch)
+	c.connTCPState.Describe(ch)
+	c.connList.Describe(ch)
+}
+
+func (c *addressListCollector) Collect(ctx *metrics.CollectorContext) error {
+	return errors.Join(
+		c.collectAddressLists(ctx),
+		c.collectConnections(ctx),
+		c.collectConnectionsByList(ctx),
+	)
+}
+
+func (c *addressListCollector) collectAddressLists(ctx *metrics.CollectorContext) error {
+	lists, err := ctx.FeatureCfg.Strs("lists")
+	if err != nil {
+		return fmt.Errorf("get lists error: %w", err)
+	}
+
+	var errs error
+
+	for _, topic := range c.topics {
+		if topic == "ipv6" && ctx.Device.IPv6Disabled {
+			continue
+		}
+
+		ipVersion := "4"
+		if topic == "ipv6" {
+			ipVersion = "6"
+		}
+
+		if len(lists) == 0 {
+			errs = errors.Join(errs, c.collectAllAddressLists(ctx, topic, ipVersion))
+
+			continue
+		}
+
+		for _, list := range lists {
+			errs = errors.Join(errs, c.collectAddressList(ctx, topic, ipVersion, list))
+		}
+	}
+
+	return errs
+}
+
+// collectAllAddressLists load all entries (only necessary properties) and count them by list.
+func (c *addressListCollector) collectAllAddressLists(ctx *metrics.CollectorContext, topic, ipVersion string,
+) error {
+	reply, err := ctx.Client.Run("/"+topic+"/firewall/address-list/print", "?disabled=false",
+		"=.proplist=list,dynamic,timeout")
+	if err != nil {
+		return fmt.Errorf("fetch %s address-list error: %w", topic, err)
+	}
+
+	type listType struct{ list, typ string }
+
+	entries := make(map[listType]int)
+	timeouts := make(map[string]int)
+
+	for _, re := range reply.Re {
+		list := re.Map["list"]
+
+		// report 0 for missing type and timeouts in list
+		if _, ok := timeouts[list]; !ok {
+			timeouts[list] = 0
+			entries[listType{list, "dynamic"}] = 0
+			entries[listType{list, "static"}] = 0
+		}
+
+		if re.Map["dynamic"] == "true" {
+			entries[listType{list, "dynamic"}]++
+		} else {
+			entries[listType{list, "static"}]++
+		}
+
+		if re.Map["timeout"] != "" {
+			timeouts[list]++
+		}
+	}
+
+	psEntries, _ := c.entries.(metrics.PropertySimpleSet)
+	psTimeout, _ := c.withTimeout.(metrics.PropertySimpleSet)
+
+	var errs error
+
+	for lt, cnt := range entries {
+		lctx := ctx.WithLabels(ipVersion, lt.list, lt.typ)
+		errs = errors.Join(errs, psEntries.Set(float64(cnt), &lctx))
+	}
+
+	for list, cnt := range timeouts {
+		lctx := ctx.WithLabels(ipVersion, list)
+		errs = errors.Join(errs, psTimeout.Set(float64(cnt), &lctx))
+	}
+
+	return errs
+}
+
+// collectAddressList count entries in given list using count-only queries.
+func (c *addressListCollector) collectAddressList(ctx *metrics.CollectorContext, topic, ipVersion, list string,
+) error {
+	cmd := "/" + topic + "/firewall/address-list/print"
+
+	var errs error
+
+	for _, typ := range []string{"dynamic", "static"} {
+		dynamic := "false"
+		if typ == "dynamic" {
+			dynamic = "true"
+		}
+
+		lctx := ctx.WithLabels(ipVersion, list, typ)
+
+		if err := c.count(&lctx, c.entries, cmd, "?disabled=false", "?list="+list, "?dynamic="+dynamic); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("count %s address-list %s error: %w", topic, list, err))
+		}
+	}
+
+	lctx := ctx.WithLabels(ipVersion, list)
+
+	// "?timeout" match entries that have timeout property
+	if err := c.count(&lctx, c.withTimeout, cmd, "?disabled=false", "?list="+list, "?timeout"); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("count %s address-list %s error: %w", topic, list, err))
+	}
+
+	return errs
+}
+
+// collectConnections count tracked connections by protocol and tcp state; enabled by
+// `connections` option as it require one count-only query per protocol and state.
+func (c *addressListCollector) collectConnections(ctx *metrics.CollectorContext) error {
+	if !ctx.FeatureCfg.BoolValue("connections", false) {
+		return nil
+	}
+
+	protocols, err := ctx.FeatureCfg.Strs("protocols")
+	if err != nil {
+		return fmt.Errorf("get protocols error: %w", err)
+	}
+
+	var errs error
+
+	for _, topic := range c.topics {
+		if topic == "ipv6" && ctx.Device.IPv6Disabled {
+			continue
+		}
+
+		ipVersion := "4"
+		if topic == "ipv6" {
+			ipVersion = "6"
+		}
+
+		topicProtocols := protocols
+		if len(topicProtocols) == 0 {
+			topicProtocols = c.protocols[topic]
+		}
+
+		errs = errors.Join(errs, c.collectTopicConnections(ctx, topic, ipVersion, topicProtocols))
+	}
+
+	return errs
+}
+
+func (c *addressListCollector) collectTopicConnections(ctx *metrics.CollectorContext,
+	topic, ipVersion string, protocols []string,
+) error {
+	cmd := "/" + topic + "/firewall/connection/print"
+
+	var errs error
+
+	for _, protocol := range protocols {
+		lctx := ctx.WithLabels(ipVersion, protocol)
+
+		if err := c.count(&lctx, c.connProtocol, cmd, "?protocol="+protocol); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("count %s connections for %s error: %w", topic, protocol, err))
+		}
+	}
+
+	for _, state := range c.tcpStates {
+		lctx := ctx.WithLabels(ipVersion, state)
+
+		if err := c.count(&lctx, c.connTCPState, cmd, "?protocol=tcp", "?tcp-state="+state); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("count %s connections in %s state error: %w", topic, state, err))
+		}
+	}
+
+	return errs
+}
+
+// collectConnectionsByList count connections with source or destination address in configured
+// `connection_lists`.
+//
+// This is expensive: API can't filter connections by address-list so addresses of ALL tracked
+// connections are loaded on each scrape. To protect device and exporter, connections are counted
+// first and table bigger than `max_connections` (default 10000) is skipped with error.
+func (c *addressListCollector) collectConnectionsByList(ctx *metrics.CollectorContext) error {
+	lists, err := ctx.FeatureCfg.Strs("connection_lists")
+	if err != nil {
+		return fmt.Errorf("get connection_lists error: %w", err)
+	}
+
+	if len(lists) == 0 {
+		return nil
+	}
+
+	maxConnections := ctx.FeatureCfg.IntValue("max_connections", defaultMaxConnections)
+
+	var errs error
+
+	for _, topic := range c.topics {
+		if topic == "ipv6" && ctx.Device.IPv6Disabled {
+			continue
+		}
+
+		ipVersion := "4"
+		if topic == "ipv6" {
+			ipVersion = "6"
+		}
+
+		errs = errors.Join(errs, c.collectTopicConnectionsByList(ctx, topic, ipVersion, lists, maxConnections))
+	}
+
+	return errs
+}
+
+func (c *addressListCollector) collectTopicConnectionsByList(ctx *metrics.CollectorContext,
+	topic, ipVersion string, lists []string, maxConnections int,
+) error {
+	cmd := "/" + topic + "/firewall/connection/print"
+
+	reply, err := ctx.Client.Run(cmd, "=count-only=")
+	if err != nil {
+		return fmt.Errorf("count %s connections error: %w", topic, err)
+	}
+
+	total, err := convert.MetricFromString(reply.Done.Map["ret"])
+	if err != nil {
+		return fmt.Errorf("parse %s connections count error: %w", topic, err)
+	}
+
+	if total > float64(maxConnections) {
+		return fmt.Errorf("count %s connections by address-list: %w (%v > max_connections %d)",
+			topic, ErrTooManyConnections, total, maxConnections)
+	}
+
+	matchers := make(map[string]addressMatcher, len(lists))
+
+	for _, list := range lists {
+		reply, err := ctx.Client.Run("/"+topic+"/firewall/address-list/print", "?disabled=false", "?list="+list,
+			"=.proplist=address")
+		if err != nil {
+			return fmt.Errorf("fetch %s address-list %s error: %w", topic, list, err)
+		}
+
+		matchers[list] = newAddressMatcher(convert.ExtractPropertyFromReplay(reply, "address"))
+	}
+
+	reply, err = ctx.Client.Run(cmd, "=.proplist=src-address,dst-address")
+	if err != nil {
+		return fmt.Errorf("fetch %s connections error: %w", topic, err)
+	}
+
+	psConnList, _ := c.connList.(metrics.PropertySimpleSet)
+
+	var errs error
+
+	for list, m := range matchers {
+		src, dst := 0, 0
+
+		for _, re := range reply.Re {
+			if m.match(re.Map["src-address"]) {
+				src++
+			}
+
+			if m.match(re.Map["dst-address"]) {
+				dst++
+			}
+		}
+
+		lctx := ctx.WithLabels(ipVersion, list, "src")
+		errs = errors.Join(errs, psConnList.Set(float64(src), &lctx))
+		lctx = ctx.WithLabels(ipVersion, list, "dst")
+		errs = errors.Join(errs, psConnList.Set(float64(dst), &lctx))
+	}
+
+	return errs
+}
+
+// count run count-only query and collect result into `metric`.
+func (c *addressListCollector) count(ctx *metrics.CollectorContext, metric metrics.PropertyMetric,
+	cmd string, query ...string,
+) error {
+	args := append([]string{cmd}, query...)
+	args = append(args, "=count-only=")
+
+	reply, err := ctx.Client.Run(args...)
+	if err != nil {
+		return fmt.Errorf("fetch %s error: %w", cmd, err)
+	}
+
+	if err := metric.Collect(reply.Done.Map, ctx); err != nil {
+		return fmt.Errorf("collect %s count error: %w", cmd, err)
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// addressMatcher check if address belong to address-list entries (addresses, prefixes and ranges).
+// Entries with dns names are ignored.
+type addressMatcher struct {
+	addrs    map[netip.Addr]struct{}
+	prefixes []netip.Prefix
+	ranges   [][2]netip.Addr
+}
+
+func newAddressMatcher(entries []string) addressMatcher {
+	m := addressMatcher{addrs: make(map[netip.Addr]struct{})}
+
+	for _, e := range entries {
+		if from, to, ok := strings.Cut(e, "-"); ok {
+			f, ferr := netip.ParseAddr(from)
+			t, terr := netip.ParseAddr(to)
+
+			if ferr == nil && terr == nil {
+				m.ranges = append(m.ranges, [2]netip.Addr{f, t})
+			}
+		} else if p, err := netip.ParsePrefix(e); err == nil {
+			m.prefixes = append(m.prefixes, p)
+		} else if a, err := netip.ParseAddr(e); err == nil {
+			m.addrs[a] = struct{}{}
+		}
+	}
+
+	return m
+}
+
+func (m addressMatcher) match(address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		// connection addresses contain port
+		ap, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return false
+		}
+
+		addr = ap.Addr()
+	}
+
+	if _, ok := m.addrs[addr]; ok {
+		return true
+	}
+
+	for _, p := range m.prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	for _, r := range m.ranges {
+		if r[0].Compare(addr) <= 0 && addr.Compare(r[1]) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
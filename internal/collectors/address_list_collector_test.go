@@ -0,0 +1,50 @@
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressMatcherMatch(t *testing.T) {
+	m := newAddressMatcher([]string{
+		"192.168.1.10",
+		"10.0.0.0/8",
+		"172.16.0.10-172.16.0.20",
+		"2001:db8::1",
+		"2001:db8:1::/48",
+		"example.com",
+		"invalid-range",
+	})
+
+	tests := []struct {
+		address string
+		match   bool
+	}{
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"192.168.1.10:443", true},
+		{"10.1.2.3", true},
+		{"10.1.2.3:53", true},
+		{"11.0.0.1", false},
+		{"172.16.0.10", true},
+		{"172.16.0.15:8080", true},
+		{"172.16.0.20", true},
+		{"172.16.0.21", false},
+		{"172.16.0.9", false},
+		{"2001:db8::1", true},
+		{"[2001:db8::1]:443", true},
+		{"2001:db8::2", false},
+		{"2001:db8:1:2::5", true},
+		{"[2001:db8:1::5]:80", true},
+		{"2001:db8:2::5", false},
+		{"example.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.match, m.match(tt.address))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("bgp", newBGPCollector,
+		"retrieves BGP sessions (v7) or peers (v6) metrics")
+}
+
+type bgpCollector struct {
+	common     metrics.PropertyMetricList
+	v7         metrics.PropertyMetricList
+	v6         metrics.PropertyMetricList
+	advertised metrics.PropertyMetric
+}
+
+func newBGPCollector() RouterOSCollector {
+	const prefix = "bgp"
+
+	// vrf is set only on v7, instance only on v6
+	labelNames := []string{"name", "remote_address", "remote_as", "vrf", "instance"}
+
+	return &bgpCollector{
+		common: metrics.PropertyMetricList{
+			metrics.NewPropertyStatusMetric(prefix, "state",
+				[]string{"idle", "connect", "active", "opensent", "openconfirm", "established"},
+				labelNames...).
+				WithHelp("BGP session state").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "uptime", labelNames...).
+				WithConverter(convert.MetricFromDuration).
+				WithHelp("BGP session uptime in seconds").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "prefix-count", labelNames...).
+				WithName("prefix_received").
+				WithHelp("number of prefixes received from peer").
+				Build(),
+		},
+		v7: metrics.PropertyMetricList{
+			metrics.NewPropertyCounterMetric(prefix, "remote.messages", labelNames...).
+				WithName("messages_received_total").
+				WithHelp("number of messages received from peer").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "local.messages", labelNames...).
+				WithName("messages_sent_total").
+				WithHelp("number of messages sent to peer").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "remote.bytes", labelNames...).
+				WithName("received_bytes_total").
+				WithHelp("number of bytes received from peer").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "local.bytes", labelNames...).
+				WithName("sent_bytes_total").
+				WithHelp("number of bytes sent to peer").
+				Build(),
+		},
+		v6: metrics.PropertyMetricList{
+			metrics.NewPropertyCounterMetric(prefix, "updates-received", labelNames...).
+				WithName("updates_received_total").
+				WithHelp("number of update messages received from peer").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "updates-sent", labelNames...).
+				WithName("updates_sent_total").
+				WithHelp("number of update messages sent to peer").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "withdrawn-received", labelNames...).
+				WithName("withdrawn_received_total").
+				WithHelp("number of withdraw messages received from peer").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "withdrawn-sent", labelNames...).
+				WithName("withdrawn_sent_total").
+				WithHelp("number of withdraw messages sent to peer").
+				Build(),
+		},
+		advertised: metrics.NewPropertyRetMetric(prefix, "prefix_advertised", labelNames...).
+			WithHelp("number of prefixes advertised to peer").
+			Build(),
+	}
+}
+
+func (c *bgpCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.common.Describe(ch)
+	c.v7.Describe(ch)
+	c.v6.Describe(ch)
+	c.advertised.Describe(ch)
+}
+
+func (c *bgpCollector) Collect(ctx *metrics.CollectorContext) error {
+	if ctx.Device.FirmwareVersion.Major < 7 { //nolint:mnd
+		return c.collectV6(ctx)
+	}
+
+	return c.collectV7(ctx)
+}
+
+func (c *bgpCollector) collectV7(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/routing/bgp/session/print",
+		"=.proplist=name,remote.address,remote.as,vrf,established,state,uptime,prefix-count,"+
+			"remote.messages,local.messages,remote.bytes,local.bytes")
+	if err != nil {
+		return fmt.Errorf("fetch bgp session error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		vrf := re.Map["vrf"]
+		if vrf == "" {
+			vrf = "main"
+		}
+
+		// older v7 releases report only "established" flag instead of state
+		if re.Map["state"] == "" {
+			if re.Map["established"] == "true" {
+				re.Map["state"] = "established"
+			} else {
+				re.Map["state"] = "idle"
+			}
+		}
+
+		lctx := ctx.WithLabels(re.Map["name"], re.Map["remote.address"], re.Map["remote.as"], vrf, "")
+
+		if err := c.common.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bgp session %v error: %w", re, err))
+		}
+
+		if err := c.v7.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bgp session %v error: %w", re, err))
+		}
+
+		if err := c.collectAdvertised(re.Map["name"], &lctx); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (c *bgpCollector) collectV6(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/routing/bgp/peer/print", "?disabled=false",
+		"=.proplist=name,instance,remote-address,remote-as,state,uptime,prefix-count,"+
+			"updates-received,updates-sent,withdrawn-received,withdrawn-sent")
+	if err != nil {
+		return fmt.Errorf("fetch bgp peer error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		// v6 has no vrf on peer
+		lctx := ctx.WithLabels(re.Map["name"], re.Map["remote-address"], re.Map["remote-as"], "",
+			re.Map["instance"])
+
+		if err := c.common.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bgp peer %v error: %w", re, err))
+		}
+
+		if err := c.v6.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bgp peer %v error: %w", re, err))
+		}
+
+		if err := c.collectAdvertised(re.Map["name"], &lctx); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// collectAdvertised count prefixes advertised to peer; disabled by default as
+// it require one additional call for each peer.
+func (c *bgpCollector) collectAdvertised(peer string, ctx *metrics.CollectorContext) error {
+	if !ctx.FeatureCfg.BoolValue("advertised", false) {
+		return nil
+	}
+
+	reply, err := ctx.Client.Run("/routing/bgp/advertisements/print", "?peer="+peer, "=count-only=")
+	if err != nil {
+		return fmt.Errorf("fetch bgp advertisements for %s error: %w", peer, err)
+	}
+
+	if err := c.advertised.Collect(reply.Done.Map, ctx); err != nil {
+		return fmt.Errorf("collect bgp advertisements for %s error: %w", peer, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("bridge", newBridgeCollector,
+		"retrieves bridge, stp, hosts and vlan metrics")
+}
+
+type bridgeCollector struct {
+	bridges     metrics.PropertyMetricList
+	rootInfo    metrics.PropertyMetric
+	ports       metrics.PropertyMetricList
+	host        metrics.PropertyMetric
+	vlan        metrics.PropertyMetric
+	hosts       *prometheus.Desc
+	rootChanges *prometheus.Desc
+
+	// last seen root bridge id for each device and bridge; bridges not found on last
+	// scrape are removed. Devices are removed by RemoveDevice.
+	roots   map[*config.Device]map[string]bridgeRoot
+	rootsMu sync.Mutex
+}
+
+type bridgeRoot struct {
+	id      string
+	changes int
+}
+
+func newBridgeCollector() RouterOSCollector {
+	const prefix = "bridge"
+
+	bridgeLabels := []string{"bridge"}
+	rootLabels := []string{"bridge", "root_bridge_id", "root_port"}
+	portLabels := []string{"bridge", metrics.LabelInterface}
+	hostLabels := []string{"bridge", metrics.LabelInterface, "mac_address", "vid"}
+	vlanLabels := []string{"bridge", "vlan_ids", "tagged", "untagged"}
+
+	return &bridgeCollector{
+		bridges: metrics.PropertyMetricList{
+			metrics.NewPropertyGaugeMetric(prefix, "root-bridge", bridgeLabels...).
+				WithConverter(convert.MetricFromBool).
+				WithHelp("bridge is root bridge").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "root-path-cost", bridgeLabels...).
+				WithHelp("cost of path to root bridge").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "port-count", bridgeLabels...).
+				WithHelp("number of bridge ports").
+				Build(),
+		},
+		rootInfo: metrics.NewPropertyConstMetric(prefix, "root-bridge-id", rootLabels...).
+			WithName("root_info").
+			WithHelp("current root bridge and root port").
+			Build(),
+		ports: metrics.PropertyMetricList{
+			// role is normalized (without "-port" suffix)
+			metrics.NewPropertyStatusMetric(prefix, "role",
+				[]string{"root", "designated", "alternate", "backup", "disabled"}, portLabels...).
+				WithName("port_role").
+				WithHelp("bridge port STP role").
+				Build(),
+			metrics.NewPropertyStatusMetric(prefix, "state",
+				[]string{"forwarding", "learning", "discarding"}, portLabels...).
+				WithName("port_state").
+				WithHelp("bridge port STP state").
+				Build(),
+		},
+		host: metrics.NewPropertyConstMetric(prefix, "mac-address", hostLabels...).
+			WithName("host").
+			WithHelp("host learned on bridge port").
+			Build(),
+		vlan: metrics.NewPropertyConstMetric(prefix, "vlan-ids", vlanLabels...).
+			WithName("vlan_info").
+			WithHelp("bridge vlan membership").
+			Build(),
+		hosts: metrics.Description(prefix, "hosts", "number of hosts learned on bridge port",
+			metrics.LabelDevName, metrics.LabelDevAddress, "bridge", metrics.LabelInterface),
+		rootChanges: metrics.Description(prefix, "root_changes_total",
+			"number of root bridge changes observed by exporter",
+			metrics.LabelDevName, metrics.LabelDevAddress, "bridge"),
+
+		roots: make(map[*config.Device]map[string]bridgeRoot),
+	}
+}
+
+func (c *bridgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.bridges.Describe(ch)
+	c.rootInfo.Describe(ch)
+	c.ports.Describe(ch)
+	c.host.Describe(ch)
+	c.vlan.Describe(ch)
+	ch <- c.hosts
+	ch <- c.rootChanges
+}
+
+func (c *bridgeCollector) Collect(ctx *metrics.CollectorContext) error {
+	return errors.Join(
+		c.collectBridges(ctx),
+		c.collectPorts(ctx),
+		c.collectHosts(ctx),
+		c.collectVLANs(ctx),
+	)
+}
+
+func (c *bridgeCollector) collectBridges(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/interface/bridge/print", "?disabled=false", "=.proplist=name")
+	if err != nil {
+		return fmt.Errorf("fetch bridge error: %w", err)
+	}
+
+	bridges := convert.ExtractPropertyFromReplay(reply, "name")
+	if len(bridges) == 0 {
+		c.updateRoots(ctx.Device, nil)
+
+		return nil
+	}
+
+	reply, err = ctx.Client.Run("/interface/bridge/monitor",
+		"=numbers="+strings.Join(bridges, ","),
+		"=once=",
+		"=.proplist=name,root-bridge,root-bridge-id,root-port,root-path-cost,port-count")
+	if err != nil {
+		return fmt.Errorf("get bridge monitor error: %w", err)
+	}
+
+	var errs error
+
+	roots := make(map[string]string, len(reply.Re))
+
+	for _, re := range reply.Re {
+		name := re.Map["name"]
+		roots[name] = re.Map["root-bridge-id"]
+
+		lctx := ctx.WithLabels(name)
+
+		if err := c.bridges.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bridge %v error: %w", name, err))
+		}
+
+		lctx = ctx.WithLabels(name, re.Map["root-bridge-id"], re.Map["root-port"])
+
+		if err := c.rootInfo.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bridge %v error: %w", name, err))
+		}
+	}
+
+	for name, changes := range c.updateRoots(ctx.Device, roots) {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.rootChanges, prometheus.CounterValue, float64(changes),
+			ctx.Device.Name, ctx.Device.Address, name)
+	}
+
+	return errs
+}
+
+// updateRoots remember root bridge id for each bridge of device and return number of changes
+// of root bridge for each bridge. Bridges not in `roots` are forgotten.
+func (c *bridgeCollector) updateRoots(device *config.Device, roots map[string]string) map[string]int {
+	c.rootsMu.Lock()
+	defer c.rootsMu.Unlock()
+
+	prev := c.roots[device]
+	current := make(map[string]bridgeRoot, len(roots))
+	changes := make(map[string]int, len(roots))
+
+	for bridge, rootID := range roots {
+		root, ok := prev[bridge]
+		if ok && root.id != rootID {
+			root.changes++
+		}
+
+		root.id = rootID
+		current[bridge] = root
+		changes[bridge] = root.changes
+	}
+
+	c.roots[device] = current
+
+	return changes
+}
+
+// RemoveDevice implements DeviceStateCollector interface.
+func (c *bridgeCollector) RemoveDevice(device *config.Device) {
+	c.rootsMu.Lock()
+	defer c.rootsMu.Unlock()
+
+	delete(c.roots, device)
+}
+
+func (c *bridgeCollector) collectPorts(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/interface/bridge/port/print", "?disabled=false",
+		"=.proplist=bridge,interface,role,forwarding,learning")
+	if err != nil {
+		return fmt.Errorf("fetch bridge port error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		re.Map["role"] = strings.TrimSuffix(re.Map["role"], "-port")
+
+		switch {
+		case re.Map["forwarding"] == "true":
+			re.Map["state"] = "forwarding"
+		case re.Map["learning"] == "true":
+			re.Map["state"] = "learning"
+		default:
+			re.Map["state"] = "discarding"
+		}
+
+		lctx := ctx.WithLabelsFromMap(re.Map, "bridge", "interface")
+
+		if err := c.ports.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bridge port %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *bridgeCollector) collectHosts(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/interface/bridge/host/print", "?local=false",
+		"=.proplist=bridge,on-interface,mac-address,vid")
+	if err != nil {
+		return fmt.Errorf("fetch bridge host error: %w", err)
+	}
+
+	type port struct{ bridge, iface string }
+
+	counts := make(map[port]int)
+
+	for _, re := range reply.Re {
+		counts[port{re.Map["bridge"], re.Map["on-interface"]}]++
+	}
+
+	for p, cnt := range counts {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.hosts, prometheus.GaugeValue, float64(cnt),
+			ctx.Device.Name, ctx.Device.Address, p.bridge, p.iface)
+	}
+
+	// do not load entries if not configured
+	if !ctx.FeatureCfg.BoolValue("details", false) {
+		return nil
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "bridge", "on-interface", "mac-address", "vid")
+
+		if err := c.host.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bridge host %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *bridgeCollector) collectVLANs(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/interface/bridge/vlan/print", "?disabled=false",
+		"=.proplist=bridge,vlan-ids,current-tagged,current-untagged")
+	if err != nil {
+		return fmt.Errorf("fetch bridge vlan error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "bridge", "vlan-ids", "current-tagged", "current-untagged")
+
+		if err := c.vlan.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect bridge vlan %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
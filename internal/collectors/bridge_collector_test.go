@@ -0,0 +1,44 @@
+package collectors
+
+import (
+	"testing"
+
+	"mikrotik-exporter/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridgeUpdateRoots(t *testing.T) {
+	c, ok := newBridgeCollector().(*bridgeCollector)
+	assert.True(t, ok)
+
+	dev1, dev2 := &config.Device{Name: "dev1"}, &config.Device{Name: "dev2"}
+
+	changes := c.updateRoots(dev1, map[string]string{"br1": "a", "br2": "b"})
+	assert.Equal(t, map[string]int{"br1": 0, "br2": 0}, changes)
+
+	changes = c.updateRoots(dev1, map[string]string{"br1": "c", "br2": "b"})
+	assert.Equal(t, map[string]int{"br1": 1, "br2": 0}, changes)
+
+	// other device is independent
+	changes = c.updateRoots(dev2, map[string]string{"br1": "x"})
+	assert.Equal(t, map[string]int{"br1": 0}, changes)
+
+	// rename of device keep its state
+	dev1.Name = "router1"
+	changes = c.updateRoots(dev1, map[string]string{"br1": "c", "br2": "b"})
+	assert.Equal(t, map[string]int{"br1": 1, "br2": 0}, changes)
+
+	// removed bridge is forgotten
+	changes = c.updateRoots(dev1, map[string]string{"br1": "c"})
+	assert.Equal(t, map[string]int{"br1": 1}, changes)
+	assert.NotContains(t, c.roots[dev1], "br2")
+
+	c.updateRoots(dev1, nil)
+	assert.Empty(t, c.roots[dev1])
+
+	// removed device is forgotten
+	c.RemoveDevice(dev2)
+	assert.NotContains(t, c.roots, dev2)
+	assert.Contains(t, c.roots, dev1)
+}
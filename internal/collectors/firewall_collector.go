@@ -3,10 +3,13 @@ package collectors
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 
 	"mikrotik-exporter/internal/config"
 	"mikrotik-exporter/internal/metrics"
+	"mikrotik-exporter/routeros/proto"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -15,25 +18,56 @@ func init() {
 	registerCollector("firewall", newFirewallCollector, "retrieves firewall metrics")
 }
 
+// defaultFirewallMaxRules is default limit of rules exported in `all_rules` mode.
+const defaultFirewallMaxRules = 1000
+
 type firewallCollector struct {
-	metrics metrics.PropertyMetricList
+	metrics      metrics.PropertyMetricList
+	rules        metrics.PropertyMetricList
+	rulesDropped metrics.PropertyMetric
+	rulesTables  []string
+
+	// droppedWarned keep devices for which warning about dropped rules was logged.
+	droppedWarned sync.Map
 }
 
 func newFirewallCollector() RouterOSCollector {
 	const prefix = "firewall"
 
 	labelNames := []string{"firewall", "chain", metrics.LabelComment}
+	// position is not a label to keep series stable when rules are reordered
+	ruleLabelNames := []string{
+		"ip_version", "firewall", "chain", "id", "action", metrics.LabelComment, "log_prefix",
+	}
 
 	return &firewallCollector{
 		metrics: metrics.PropertyMetricList{
 			metrics.NewPropertyCounterMetric(prefix, "packets", labelNames...).Build(),
 			metrics.NewPropertyCounterMetric(prefix, "bytes", labelNames...).Build(),
 		},
+		rules: metrics.PropertyMetricList{
+			metrics.NewPropertyCounterMetric(prefix, "packets", ruleLabelNames...).
+				WithName("rule_packets_total").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "bytes", ruleLabelNames...).
+				WithName("rule_bytes_total").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "position", ruleLabelNames...).
+				WithName("rule_position").
+				WithHelp("position of rule in firewall table").
+				Build(),
+		},
+		rulesDropped: metrics.NewPropertyGaugeMetric(prefix, "rules-dropped").
+			WithHelp("number of rules not exported due to max_rules limit").
+			Build(),
+		rulesTables: []string{"filter", "nat", "mangle", "raw"},
 	}
 }
 
 func (c *firewallCollector) Describe(ch chan<- *prometheus.Desc) {
 	c.metrics.Describe(ch)
+	c.rules.Describe(ch)
+	c.rulesDropped.Describe(ch)
 }
 
 func (c *firewallCollector) Collect(ctx *metrics.CollectorContext) error {
@@ -44,10 +78,16 @@ func (c *firewallCollector) Collect(ctx *metrics.CollectorContext) error {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	if len(fwchains) == 0 {
+	allRules := ctx.FeatureCfg.BoolValue("all_rules", false)
+
+	if len(fwchains) == 0 && !allRules {
 		return config.InvalidConfigurationError("missing configuration")
 	}
 
+	if allRules {
+		errs = errors.Join(errs, c.collectAllRules(ctx))
+	}
+
 	for _, fwc := range fwchains {
 		fw, chain, found := strings.Cut(fwc, ",")
 		if !found {
@@ -98,3 +138,106 @@ func (c *firewallCollector) collectStats(firewall, chain string, ctx *metrics.Co
 
 	return errs
 }
+
+// collectAllRules collect stats for all (enabled) rules in all tables for ipv4 and ipv6.
+func (c *firewallCollector) collectAllRules(ctx *metrics.CollectorContext) error {
+	remaining := ctx.FeatureCfg.IntValue("max_rules", defaultFirewallMaxRules)
+	dropped := 0
+
+	var errs error
+
+	for _, topic := range []string{"ip", "ipv6"} {
+		if topic == "ipv6" && ctx.Device.IPv6Disabled {
+			continue
+		}
+
+		for _, table := range c.rulesTables {
+			// ipv6 nat is available since v7
+			if topic == "ipv6" && table == "nat" && ctx.Device.FirmwareVersion.Major < 7 { //nolint:mnd
+				continue
+			}
+
+			exported, tdropped, err := c.collectRules(ctx, topic, table, remaining)
+			remaining -= exported
+			dropped += tdropped
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	c.logDropped(ctx, dropped)
+
+	psDropped, _ := c.rulesDropped.(metrics.PropertySimpleSet)
+
+	return errors.Join(errs, psDropped.Set(float64(dropped), ctx))
+}
+
+// logDropped warn once when rules start to be dropped due to `max_rules` limit.
+func (c *firewallCollector) logDropped(ctx *metrics.CollectorContext, dropped int) {
+	if dropped == 0 {
+		c.droppedWarned.Delete(ctx.Device.Name)
+
+		return
+	}
+
+	if _, warned := c.droppedWarned.LoadOrStore(ctx.Device.Name, true); !warned {
+		ctx.Logger.Warn("firewall rules limit reached; some rules are skipped",
+			"max_rules", ctx.FeatureCfg.IntValue("max_rules", defaultFirewallMaxRules), "dropped", dropped)
+	}
+}
+
+// collectRules collect stats for rules in `table` up to `limit` rules; return number of exported
+// and dropped rules.
+func (c *firewallCollector) collectRules(ctx *metrics.CollectorContext, topic, table string, limit int,
+) (int, int, error) {
+	reply, err := ctx.Client.Run("/"+topic+"/firewall/"+table+"/print", "=stats=",
+		"=.proplist=.id,chain,action,comment,log-prefix,disabled,dynamic,bytes,packets")
+	if err != nil {
+		return 0, 0, fmt.Errorf("fetch fw stats %s/%s error: %w", topic, table, err)
+	}
+
+	ipVersion := "4"
+	if topic == "ipv6" {
+		ipVersion = "6"
+	}
+
+	rules, dropped := selectFirewallRules(reply.Re, limit, ctx.FeatureCfg.BoolValue("dynamic_rules", false))
+
+	var errs error
+
+	for _, re := range rules {
+		lctx := ctx.WithLabels(ipVersion, table, re.Map["chain"], re.Map[".id"], re.Map["action"],
+			re.Map["comment"], re.Map["log-prefix"])
+
+		if err := c.rules.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect fw %s/%s error: %w", topic, table, err))
+		}
+	}
+
+	return len(rules), dropped, errs
+}
+
+// selectFirewallRules set position for each rule and select enabled rules to export (up to `limit`).
+// Position is counted as in `print` - for all entries, including disabled and dynamic.
+// Return selected rules and number of rules skipped due to limit.
+func selectFirewallRules(entries []*proto.Sentence, limit int, dynamic bool) ([]*proto.Sentence, int) {
+	rules := make([]*proto.Sentence, 0, min(len(entries), max(limit, 0)))
+	dropped := 0
+
+	for position, re := range entries {
+		re.Map["position"] = strconv.Itoa(position)
+
+		if re.Map["disabled"] == "true" || (!dynamic && re.Map["dynamic"] == "true") {
+			continue
+		}
+
+		if len(rules) >= limit {
+			dropped++
+
+			continue
+		}
+
+		rules = append(rules, re)
+	}
+
+	return rules, dropped
+}
@@ -0,0 +1,103 @@
+package collectors
+
+import (
+	"testing"
+
+	"mikrotik-exporter/routeros/proto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fwRules(rules ...map[string]string) []*proto.Sentence {
+	res := make([]*proto.Sentence, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, &proto.Sentence{Map: r})
+	}
+
+	return res
+}
+
+func TestSelectFirewallRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []map[string]string
+		limit     int
+		dynamic   bool
+		positions []string
+		dropped   int
+	}{
+		{
+			name: "dynamic rules counted in position",
+			entries: []map[string]string{
+				{"dynamic": "true"}, {}, {"dynamic": "true"}, {},
+			},
+			limit:     10,
+			positions: []string{"1", "3"},
+		},
+		{
+			name: "dynamic rules exported",
+			entries: []map[string]string{
+				{"dynamic": "true"}, {},
+			},
+			limit:     10,
+			dynamic:   true,
+			positions: []string{"0", "1"},
+		},
+		{
+			name: "disabled rules skipped",
+			entries: []map[string]string{
+				{"disabled": "true"}, {}, {"disabled": "true"},
+			},
+			limit:     1,
+			positions: []string{"1"},
+		},
+		{
+			name:      "exactly at limit",
+			entries:   []map[string]string{{}, {}},
+			limit:     2,
+			positions: []string{"0", "1"},
+		},
+		{
+			name:      "over limit",
+			entries:   []map[string]string{{}, {"disabled": "true"}, {}, {}},
+			limit:     1,
+			positions: []string{"0"},
+			dropped:   2,
+		},
+		{
+			name:      "limit exhausted",
+			entries:   []map[string]string{{}, {}},
+			limit:     0,
+			positions: []string{},
+			dropped:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, dropped := selectFirewallRules(fwRules(tt.entries...), tt.limit, tt.dynamic)
+
+			positions := make([]string, 0, len(rules))
+			for _, re := range rules {
+				positions = append(positions, re.Map["position"])
+			}
+
+			assert.Equal(t, tt.positions, positions)
+			assert.Equal(t, tt.dropped, dropped)
+		})
+	}
+}
+
+func TestSelectFirewallRulesLimitAcrossTables(t *testing.T) {
+	remaining := 3
+	dropped := 0
+
+	for _, table := range [][]map[string]string{{{}, {}}, {{}, {"dynamic": "true"}, {}}} {
+		rules, tdropped := selectFirewallRules(fwRules(table...), remaining, false)
+		remaining -= len(rules)
+		dropped += tdropped
+	}
+
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, 1, dropped)
+}
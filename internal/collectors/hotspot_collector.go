@@ -0,0 +1,165 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("hotspot", newHotspotCollector,
+		"retrieves hotspot active users and hosts metrics")
+}
+
+type hotspotCollector struct {
+	// users is metrics for each active user; enabled by "details: true".
+	users metrics.PropertyMetricList
+	// active report number of active users by server.
+	active *prometheus.Desc
+	// loginBy report number of active users by server and login method.
+	loginBy *prometheus.Desc
+	// hosts report number of hosts by server and status.
+	hosts *prometheus.Desc
+}
+
+func newHotspotCollector() RouterOSCollector {
+	const prefix = "hotspot"
+
+	labelNames := []string{"server", "user", "address", "mac_address", "login_by"}
+
+	return &hotspotCollector{
+		users: metrics.PropertyMetricList{
+			metrics.NewPropertyGaugeMetric(prefix, "uptime", labelNames...).
+				WithName("user_uptime").
+				WithConverter(convert.MetricFromDuration).
+				WithHelp("hotspot user session uptime in seconds").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "bytes-in", labelNames...).
+				WithName("user_bytes_in_total").
+				WithHelp("number of bytes received from user").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "bytes-out", labelNames...).
+				WithName("user_bytes_out_total").
+				WithHelp("number of bytes sent to user").
+				Build(),
+		},
+		active: metrics.Description(prefix, "active_users", "number of active hotspot users",
+			metrics.LabelDevName, metrics.LabelDevAddress, "server"),
+		loginBy: metrics.Description(prefix, "active_users_by_login", "number of active hotspot users by login method",
+			metrics.LabelDevName, metrics.LabelDevAddress, "server", "login_by"),
+		hosts: metrics.Description(prefix, "hosts", "number of hotspot hosts by status",
+			metrics.LabelDevName, metrics.LabelDevAddress, "server", "status"),
+	}
+}
+
+func (c *hotspotCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.users.Describe(ch)
+	ch <- c.active
+	ch <- c.loginBy
+	ch <- c.hosts
+}
+
+func (c *hotspotCollector) Collect(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/ip/hotspot/print", "?disabled=false", "=.proplist=name")
+	if err != nil {
+		return fmt.Errorf("fetch hotspot error: %w", err)
+	}
+
+	servers := convert.ExtractPropertyFromReplay(reply, "name")
+
+	return errors.Join(
+		c.collectActive(ctx, servers),
+		c.collectHosts(ctx, servers),
+	)
+}
+
+func (c *hotspotCollector) collectActive(ctx *metrics.CollectorContext, servers []string) error {
+	reply, err := ctx.Client.Run("/ip/hotspot/active/print",
+		"=.proplist=server,user,address,mac-address,login-by,uptime,bytes-in,bytes-out")
+	if err != nil {
+		return fmt.Errorf("fetch hotspot active error: %w", err)
+	}
+
+	// report 0 for servers without active users
+	active := make(map[string]int, len(servers))
+	for _, s := range servers {
+		active[s] = 0
+	}
+
+	type serverLogin struct{ server, loginBy string }
+
+	loginBy := make(map[serverLogin]int)
+
+	for _, re := range reply.Re {
+		active[re.Map["server"]]++
+		loginBy[serverLogin{re.Map["server"], re.Map["login-by"]}]++
+	}
+
+	for server, count := range active {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(count),
+			ctx.Device.Name, ctx.Device.Address, server)
+	}
+
+	for sl, count := range loginBy {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.loginBy, prometheus.GaugeValue, float64(count),
+			ctx.Device.Name, ctx.Device.Address, sl.server, sl.loginBy)
+	}
+
+	// do not load entries if not configured
+	if !ctx.FeatureCfg.BoolValue("details", false) {
+		return nil
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "server", "user", "address", "mac-address", "login-by")
+
+		if err := c.users.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect hotspot active %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *hotspotCollector) collectHosts(ctx *metrics.CollectorContext, servers []string) error {
+	reply, err := ctx.Client.Run("/ip/hotspot/host/print", "=.proplist=server,authorized,bypassed")
+	if err != nil {
+		return fmt.Errorf("fetch hotspot host error: %w", err)
+	}
+
+	type serverStatus struct{ server, status string }
+
+	hosts := make(map[serverStatus]int, len(servers)*3) //nolint:mnd
+
+	for _, s := range servers {
+		for _, st := range []string{"authorized", "bypassed", "unauthorized"} {
+			hosts[serverStatus{s, st}] = 0
+		}
+	}
+
+	for _, re := range reply.Re {
+		status := "unauthorized"
+
+		switch {
+		case re.Map["authorized"] == "true":
+			status = "authorized"
+		case re.Map["bypassed"] == "true":
+			status = "bypassed"
+		}
+
+		hosts[serverStatus{re.Map["server"], status}]++
+	}
+
+	for ss, count := range hosts {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.hosts, prometheus.GaugeValue, float64(count),
+			ctx.Device.Name, ctx.Device.Address, ss.server, ss.status)
+	}
+
+	return nil
+}
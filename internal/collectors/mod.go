@@ -8,6 +8,7 @@ import (
 	"maps"
 	"slices"
 
+	"mikrotik-exporter/internal/config"
 	"mikrotik-exporter/internal/metrics"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,6 +21,13 @@ type RouterOSCollector interface {
 	Collect(ctx *metrics.CollectorContext) error
 }
 
+// DeviceStateCollector is implemented by collectors that keep state of devices between
+// scrapes. State is keyed by device pointer as device name may change.
+type DeviceStateCollector interface {
+	// RemoveDevice forget state of removed `device`.
+	RemoveDevice(device *config.Device)
+}
+
 // ----------------------------------------------------------------------------
 
 type RegisteredCollector struct {
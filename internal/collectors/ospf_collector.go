@@ -0,0 +1,146 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("ospf", newOSPFCollector,
+		"retrieves OSPF neighbors, instances and interface templates metrics")
+}
+
+type ospfCollector struct {
+	neighbors metrics.PropertyMetricList
+	instances metrics.PropertyMetricList
+	templates metrics.PropertyMetricList
+}
+
+func newOSPFCollector() RouterOSCollector {
+	const prefix = "ospf"
+
+	neighborLabels := []string{"instance", "area", metrics.LabelInterface, "address", "router_id"}
+	instanceLabels := []string{"instance", "version", "vrf", "router_id"}
+	templateLabels := []string{"area", "interfaces", "networks", "type"}
+
+	return &ospfCollector{
+		neighbors: metrics.PropertyMetricList{
+			// state is normalized (lower case, without "-") to get valid metric names.
+			metrics.NewPropertyStatusMetric(prefix, "state",
+				[]string{"down", "attempt", "init", "2way", "exstart", "exchange", "loading", "full"},
+				neighborLabels...).
+				WithName("neighbor_state").
+				WithHelp("OSPF neighbor state").
+				Build(),
+			metrics.NewPropertyCounterMetric(prefix, "state-changes", neighborLabels...).
+				WithName("neighbor_state_changes_total").
+				WithHelp("number of OSPF neighbor state changes").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "adjacency", neighborLabels...).
+				WithName("neighbor_adjacency").
+				WithConverter(convert.MetricFromDuration).
+				WithHelp("OSPF neighbor adjacency uptime in seconds").
+				Build(),
+		},
+		instances: metrics.PropertyMetricList{
+			metrics.NewPropertyConstMetric(prefix, "name", instanceLabels...).
+				WithName("instance_info").
+				WithHelp("OSPF instance information").
+				Build(),
+		},
+		templates: metrics.PropertyMetricList{
+			metrics.NewPropertyGaugeMetric(prefix, "cost", templateLabels...).
+				WithName("interface_template_cost").
+				WithHelp("OSPF interface template cost").
+				Build(),
+		},
+	}
+}
+
+func (c *ospfCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.neighbors.Describe(ch)
+	c.instances.Describe(ch)
+	c.templates.Describe(ch)
+}
+
+func (c *ospfCollector) Collect(ctx *metrics.CollectorContext) error {
+	if ctx.Device.FirmwareVersion.Major < 7 { //nolint:mnd
+		return c.collectNeighbors(ctx,
+			"=.proplist=instance,address,interface,router-id,state,state-changes,adjacency")
+	}
+
+	return errors.Join(
+		c.collectNeighbors(ctx,
+			"=.proplist=instance,area,address,interface,router-id,state,state-changes,adjacency"),
+		c.collectInstances(ctx),
+		c.collectTemplates(ctx),
+	)
+}
+
+func (c *ospfCollector) collectNeighbors(ctx *metrics.CollectorContext, proplist string) error {
+	reply, err := ctx.Client.Run("/routing/ospf/neighbor/print", proplist)
+	if err != nil {
+		return fmt.Errorf("fetch ospf neighbor error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		re.Map["state"] = strings.ReplaceAll(strings.ToLower(re.Map["state"]), "-", "")
+
+		// v6 does not report area for neighbors; label is empty
+		lctx := ctx.WithLabelsFromMap(re.Map, "instance", "area", "interface", "address", "router-id")
+
+		if err := c.neighbors.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect ospf neighbor %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *ospfCollector) collectInstances(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/routing/ospf/instance/print", "?disabled=false",
+		"=.proplist=name,version,vrf,router-id")
+	if err != nil {
+		return fmt.Errorf("fetch ospf instance error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "name", "version", "vrf", "router-id")
+
+		if err := c.instances.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect ospf instance %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *ospfCollector) collectTemplates(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/routing/ospf/interface-template/print", "?disabled=false",
+		"=.proplist=area,interfaces,networks,type,cost")
+	if err != nil {
+		return fmt.Errorf("fetch ospf interface-template error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "area", "interfaces", "networks", "type")
+
+		if err := c.templates.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect ospf interface-template %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,210 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+	"mikrotik-exporter/routeros/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("ping", newPingCollector,
+		"run ping from device to configured targets")
+}
+
+var ErrMissingPingAddress = errors.New("missing address for ping target")
+
+// pingArgs map target configuration keys to /ping arguments.
+var pingArgs = [][2]string{
+	{"count", "count"},
+	{"size", "size"},
+	{"interval", "interval"},
+	{"interface", "interface"},
+	{"routing_table", "routing-table"},
+	{"vrf", "vrf"},
+	{"src_address", "src-address"},
+}
+
+const defaultPingCount = "3"
+
+type pingCollector struct {
+	rttMin *prometheus.Desc
+	rttAvg *prometheus.Desc
+	rttMax *prometheus.Desc
+	jitter *prometheus.Desc
+	loss   *prometheus.Desc
+}
+
+func newPingCollector() RouterOSCollector {
+	const prefix = "ping"
+
+	labelNames := []string{metrics.LabelDevName, metrics.LabelDevAddress, "target", "address"}
+
+	return &pingCollector{
+		rttMin: metrics.Description(prefix, "rtt_min", "minimal round trip time in seconds", labelNames...),
+		rttAvg: metrics.Description(prefix, "rtt_avg", "average round trip time in seconds", labelNames...),
+		rttMax: metrics.Description(prefix, "rtt_max", "maximal round trip time in seconds", labelNames...),
+		jitter: metrics.Description(prefix, "jitter",
+			"mean difference of round trip time between consecutive packets in seconds", labelNames...),
+		loss: metrics.Description(prefix, "loss_ratio", "ratio of lost packets (0-1)", labelNames...),
+	}
+}
+
+func (c *pingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.rttMin
+	ch <- c.rttAvg
+	ch <- c.rttMax
+	ch <- c.jitter
+	ch <- c.loss
+}
+
+func (c *pingCollector) Collect(ctx *metrics.CollectorContext) error {
+	targets, err := ctx.FeatureCfg.Maps("targets")
+	if err != nil {
+		return fmt.Errorf("get targets error: %w", err)
+	}
+
+	var errs error
+
+	for _, target := range targets {
+		if err := c.collectTarget(ctx, target); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (c *pingCollector) collectTarget(ctx *metrics.CollectorContext, target map[string]string) error {
+	address := target["address"]
+	if address == "" {
+		return fmt.Errorf("ping target %v error: %w", target, ErrMissingPingAddress)
+	}
+
+	name := target["name"]
+	if name == "" {
+		name = address
+	}
+
+	if target["count"] == "" {
+		target["count"] = defaultPingCount
+	}
+
+	args := []string{"/ping", "=address=" + address}
+
+	for _, a := range pingArgs {
+		if v := target[a[0]]; v != "" {
+			args = append(args, "="+a[1]+"="+v)
+		}
+	}
+
+	reply, err := ctx.Client.Run(args...)
+	if err != nil {
+		return fmt.Errorf("ping %s error: %w", name, err)
+	}
+
+	res, err := parsePingReply(reply.Re)
+	if err != nil {
+		return fmt.Errorf("parse ping %s reply error: %w", name, err)
+	}
+
+	labels := []string{ctx.Device.Name, ctx.Device.Address, name, address}
+
+	ctx.Ch <- prometheus.MustNewConstMetric(c.loss, prometheus.GaugeValue, res.loss, labels...)
+
+	// no packets received - rtt is unknown
+	if res.received == 0 {
+		return nil
+	}
+
+	ctx.Ch <- prometheus.MustNewConstMetric(c.rttMin, prometheus.GaugeValue, res.minRtt, labels...)
+	ctx.Ch <- prometheus.MustNewConstMetric(c.rttAvg, prometheus.GaugeValue, res.avgRtt, labels...)
+	ctx.Ch <- prometheus.MustNewConstMetric(c.rttMax, prometheus.GaugeValue, res.maxRtt, labels...)
+	ctx.Ch <- prometheus.MustNewConstMetric(c.jitter, prometheus.GaugeValue, res.jitter, labels...)
+
+	return nil
+}
+
+type pingResult struct {
+	sent, received         int
+	minRtt, avgRtt, maxRtt float64
+	jitter, loss           float64
+}
+
+// parsePingReply calculate statistics from ping replies; each reply contain time for one packet
+// (if received) and summary (sent, received).
+func parsePingReply(re []*proto.Sentence) (pingResult, error) {
+	res := pingResult{minRtt: math.Inf(1)}
+
+	var (
+		sum, prev, jitterSum float64
+		jitterCnt            int
+	)
+
+	for _, r := range re {
+		if v, err := strconv.Atoi(r.Map["sent"]); err == nil {
+			res.sent = v
+		}
+
+		rtt := r.Map["time"]
+		if rtt == "" {
+			continue
+		}
+
+		value, err := metricFromPingTime(rtt)
+		if err != nil {
+			return res, err
+		}
+
+		if res.received > 0 {
+			jitterSum += math.Abs(value - prev)
+			jitterCnt++
+		}
+
+		res.received++
+		prev = value
+		sum += value
+		res.minRtt = min(res.minRtt, value)
+		res.maxRtt = max(res.maxRtt, value)
+	}
+
+	if res.sent == 0 {
+		res.sent = len(re)
+	}
+
+	if res.sent > 0 {
+		res.loss = 1 - float64(min(res.received, res.sent))/float64(res.sent)
+	}
+
+	if res.received > 0 {
+		res.avgRtt = sum / float64(res.received)
+	}
+
+	if jitterCnt > 0 {
+		res.jitter = jitterSum / float64(jitterCnt)
+	}
+
+	return res, nil
+}
+
+// metricFromPingTime parse packet time; time may be in form like "12ms345us" or fractional
+// ("1.5ms").
+func metricFromPingTime(value string) (float64, error) {
+	if v, err := convert.MetricFromDuration(value); err == nil {
+		return v, nil
+	}
+
+	dur, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parse time %q error: %w", value, err)
+	}
+
+	return dur.Seconds(), nil
+}
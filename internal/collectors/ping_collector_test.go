@@ -0,0 +1,98 @@
+package collectors
+
+import (
+	"testing"
+
+	"mikrotik-exporter/routeros/proto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePingReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []map[string]string
+		res     pingResult
+	}{
+		{
+			name: "all received",
+			replies: []map[string]string{
+				{"time": "10ms", "sent": "1"},
+				{"time": "20ms", "sent": "2"},
+				{"time": "15ms", "sent": "3"},
+			},
+			res: pingResult{
+				sent: 3, received: 3, minRtt: 0.010, avgRtt: 0.015, maxRtt: 0.020, jitter: 0.0075,
+			},
+		},
+		{
+			name: "fractional and composite time",
+			replies: []map[string]string{
+				{"time": "1.5ms", "sent": "1"},
+				{"time": "2ms500us", "sent": "2"},
+			},
+			res: pingResult{
+				sent: 2, received: 2, minRtt: 0.0015, avgRtt: 0.002, maxRtt: 0.0025, jitter: 0.001,
+			},
+		},
+		{
+			name: "partial loss",
+			replies: []map[string]string{
+				{"time": "10ms", "sent": "1"},
+				{"status": "timeout", "sent": "2"},
+				{"time": "10ms", "sent": "3"},
+				{"status": "timeout", "sent": "4"},
+			},
+			res: pingResult{
+				sent: 4, received: 2, minRtt: 0.010, avgRtt: 0.010, maxRtt: 0.010, loss: 0.5,
+			},
+		},
+		{
+			name: "all lost",
+			replies: []map[string]string{
+				{"status": "timeout", "sent": "1"},
+				{"status": "timeout", "sent": "2"},
+			},
+			res: pingResult{sent: 2, loss: 1},
+		},
+		{
+			name: "no sent counter",
+			replies: []map[string]string{
+				{"time": "10ms"},
+				{"status": "timeout"},
+			},
+			res: pingResult{
+				sent: 2, received: 1, minRtt: 0.010, avgRtt: 0.010, maxRtt: 0.010, loss: 0.5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := make([]*proto.Sentence, 0, len(tt.replies))
+			for _, r := range tt.replies {
+				re = append(re, &proto.Sentence{Map: r})
+			}
+
+			res, err := parsePingReply(re)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.res.sent, res.sent)
+			assert.Equal(t, tt.res.received, res.received)
+			assert.InDelta(t, tt.res.loss, res.loss, 1e-9)
+			assert.InDelta(t, tt.res.jitter, res.jitter, 1e-9)
+
+			if tt.res.received > 0 {
+				assert.InDelta(t, tt.res.minRtt, res.minRtt, 1e-9)
+				assert.InDelta(t, tt.res.avgRtt, res.avgRtt, 1e-9)
+				assert.InDelta(t, tt.res.maxRtt, res.maxRtt, 1e-9)
+			}
+		})
+	}
+}
+
+func TestParsePingReplyInvalidTime(t *testing.T) {
+	_, err := parsePingReply([]*proto.Sentence{{Map: map[string]string{"time": "1.5xs"}}})
+	assert.Error(t, err)
+}
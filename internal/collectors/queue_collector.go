@@ -12,13 +12,14 @@ import (
 
 func init() {
 	registerCollector("queue", newQueueCollector,
-		"retrieves Simple Queue and queue monitor metrics")
+		"retrieves Simple Queue, Queue Tree and queue monitor metrics")
 }
 
 type queueCollector struct {
 	monitorQueuedBytes   metrics.PropertyMetric
 	monitorQueuedPackets metrics.PropertyMetric
 	metrics              metrics.PropertyMetricList
+	treeMetrics          metrics.PropertyMetricList
 }
 
 func newQueueCollector() RouterOSCollector {
@@ -26,6 +27,10 @@ func newQueueCollector() RouterOSCollector {
 
 	const sqPrefix = "simple_queue"
 
+	treeLabelNames := []string{"queue_tree_name", "parent", "packet_mark", "queue", metrics.LabelComment}
+
+	const qtPrefix = "queue_tree"
+
 	return &queueCollector{
 		monitorQueuedBytes:   metrics.NewPropertyGaugeMetric("queue", "queued-bytes").Build(),
 		monitorQueuedPackets: metrics.NewPropertyGaugeMetric("queue", "queued-packets").Build(),
@@ -44,11 +49,38 @@ func newQueueCollector() RouterOSCollector {
 				WithRxTxConverter(metricFromQueueTxRx).
 				Build(),
 		},
+		treeMetrics: metrics.PropertyMetricList{
+			metrics.NewPropertyCounterMetric(qtPrefix, "bytes", treeLabelNames...).
+				WithHelp("number of bytes processed by queue").
+				Build(),
+			metrics.NewPropertyCounterMetric(qtPrefix, "packets", treeLabelNames...).
+				WithHelp("number of packets processed by queue").
+				Build(),
+			metrics.NewPropertyCounterMetric(qtPrefix, "dropped", treeLabelNames...).
+				WithHelp("number of packets dropped by queue").
+				Build(),
+			metrics.NewPropertyGaugeMetric(qtPrefix, "queued-bytes", treeLabelNames...).
+				WithHelp("number of bytes waiting in queue").
+				Build(),
+			metrics.NewPropertyGaugeMetric(qtPrefix, "queued-packets", treeLabelNames...).
+				WithHelp("number of packets waiting in queue").
+				Build(),
+			metrics.NewPropertyGaugeMetric(qtPrefix, "rate", treeLabelNames...).
+				WithHelp("queue rate in bits per second").
+				Build(),
+			metrics.NewPropertyGaugeMetric(qtPrefix, "packet-rate", treeLabelNames...).
+				WithHelp("queue rate in packets per second").
+				Build(),
+			metrics.NewPropertyGaugeMetric(qtPrefix, "pcq-queues", treeLabelNames...).
+				WithHelp("number of PCQ sub-queues").
+				Build(),
+		},
 	}
 }
 
 func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
 	c.metrics.Describe(ch)
+	c.treeMetrics.Describe(ch)
 	c.monitorQueuedBytes.Describe(ch)
 	c.monitorQueuedPackets.Describe(ch)
 }
@@ -57,6 +89,7 @@ func (c *queueCollector) Collect(ctx *metrics.CollectorContext) error {
 	return errors.Join(
 		c.collectQueue(ctx),
 		c.collectSimpleQueue(ctx),
+		c.collectQueueTree(ctx),
 	)
 }
 
@@ -109,3 +142,31 @@ func (c *queueCollector) collectSimpleQueue(ctx *metrics.CollectorContext) error
 
 	return errs
 }
+
+func (c *queueCollector) collectQueueTree(ctx *metrics.CollectorContext) error {
+	if !ctx.FeatureCfg.BoolValue("tree", false) {
+		return nil
+	}
+
+	reply, err := ctx.Client.Run("/queue/tree/print",
+		"?disabled=false",
+		"=.proplist=name,parent,packet-mark,queue,comment,bytes,packets,dropped,queued-bytes,queued-packets,"+
+			"rate,packet-rate,pcq-queues")
+	if err != nil {
+		return fmt.Errorf("fetch queue tree error: %w", err)
+	}
+
+	var errs error
+
+	for _, reply := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(reply.Map, "name", "parent", "packet-mark", "queue", "comment")
+
+		if err := c.treeMetrics.Collect(reply.Map, &lctx); err != nil {
+			name := reply.Map["name"]
+			parent := reply.Map["parent"]
+			errs = errors.Join(errs, fmt.Errorf("collect tree %v/%v error: %w", parent, name, err))
+		}
+	}
+
+	return errs
+}
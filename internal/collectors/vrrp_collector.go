@@ -0,0 +1,132 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("vrrp", newVRRPCollector,
+		"retrieves VRRP interfaces state")
+}
+
+type vrrpCollector struct {
+	metrics metrics.PropertyMetricList
+	masters *prometheus.Desc
+}
+
+func newVRRPCollector() RouterOSCollector {
+	const prefix = "vrrp"
+
+	labelNames := []string{"name", metrics.LabelInterface, "vrid", "address"}
+
+	return &vrrpCollector{
+		metrics: metrics.PropertyMetricList{
+			metrics.NewPropertyStatusMetric(prefix, "state", []string{"master", "backup", "init"}, labelNames...).
+				WithHelp("VRRP interface state").
+				Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "priority", labelNames...).Build(),
+			metrics.NewPropertyGaugeMetric(prefix, "running", labelNames...).
+				WithConverter(convert.MetricFromBool).
+				Build(),
+		},
+		masters: metrics.Description(prefix, "masters", "number of VRRP interfaces in master state for VRID",
+			metrics.LabelDevName, metrics.LabelDevAddress, metrics.LabelInterface, "vrid"),
+	}
+}
+
+func (c *vrrpCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.metrics.Describe(ch)
+	ch <- c.masters
+}
+
+func (c *vrrpCollector) Collect(ctx *metrics.CollectorContext) error {
+	reply, err := ctx.Client.Run("/interface/vrrp/print", "?disabled=false",
+		"=.proplist=name,interface,vrid,priority,running,master,backup")
+	if err != nil {
+		return fmt.Errorf("fetch vrrp error: %w", err)
+	}
+
+	addresses, err := c.loadAddresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	type vrid struct{ iface, vrid string }
+
+	masters := make(map[vrid]int)
+
+	var errs error
+
+	for _, re := range reply.Re {
+		state := "init"
+
+		switch {
+		case re.Map["master"] == "true":
+			state = "master"
+		case re.Map["backup"] == "true":
+			state = "backup"
+		}
+
+		re.Map["state"] = state
+
+		// keep vrid with no master to report 0
+		key := vrid{re.Map["interface"], re.Map["vrid"]}
+		cnt := masters[key]
+
+		if state == "master" {
+			cnt++
+		}
+
+		masters[key] = cnt
+
+		lctx := ctx.WithLabels(re.Map["name"], re.Map["interface"], re.Map["vrid"],
+			strings.Join(addresses[re.Map["name"]], ","))
+
+		if err := c.metrics.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect vrrp %v error: %w", re, err))
+		}
+	}
+
+	for k, cnt := range masters {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.masters, prometheus.GaugeValue, float64(cnt),
+			ctx.Device.Name, ctx.Device.Address, k.iface, k.vrid)
+	}
+
+	return errs
+}
+
+// loadAddresses load virtual addresses (ipv4 and ipv6) assigned to vrrp interfaces.
+func (c *vrrpCollector) loadAddresses(ctx *metrics.CollectorContext) (map[string][]string, error) {
+	topics := []string{"/ip/address/print"}
+	if !ctx.Device.IPv6Disabled {
+		topics = append(topics, "/ipv6/address/print")
+	}
+
+	addresses := make(map[string][]string)
+
+	for _, topic := range topics {
+		reply, err := ctx.Client.Run(topic, "?disabled=false", "=.proplist=address,interface")
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s error: %w", topic, err)
+		}
+
+		for _, re := range reply.Re {
+			iface := re.Map["interface"]
+			addresses[iface] = append(addresses[iface], re.Map["address"])
+		}
+	}
+
+	for _, a := range addresses {
+		slices.Sort(a)
+	}
+
+	return addresses, nil
+}
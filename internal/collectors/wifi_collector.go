@@ -0,0 +1,275 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mikrotik-exporter/internal/convert"
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("wifi", newWifiCollector,
+		"retrieves wifi (wifiwave2) interfaces, stations and CAPsMAN remote caps metrics")
+}
+
+type wifiCollector struct {
+	radio     metrics.PropertyMetricList
+	stations  metrics.PropertyMetricList
+	remoteCap metrics.PropertyMetric
+	channel   *prometheus.Desc
+	frequency *prometheus.Desc
+	clients   *prometheus.Desc
+}
+
+func newWifiCollector() RouterOSCollector {
+	const (
+		prefix    = "wifi"
+		staPrefix = "wifi_station"
+	)
+
+	staLabelNames := []string{metrics.LabelInterface, "mac_address", "ssid"}
+	capLabelNames := []string{"identity", "address", "board", "version", "state"}
+
+	return &wifiCollector{
+		radio: metrics.PropertyMetricList{
+			metrics.NewPropertyGaugeMetric(prefix, "running", metrics.LabelInterface).
+				WithName("interface_running").
+				WithConverter(convert.MetricFromBool).
+				Build(),
+		},
+		stations: metrics.PropertyMetricList{
+			metrics.NewPropertyGaugeMetric(staPrefix, "signal", staLabelNames...).
+				WithHelp("station signal strength in dBm").
+				Build(),
+			metrics.NewPropertyGaugeMetric(staPrefix, "tx-rate", staLabelNames...).
+				WithConverter(metricFromWifiRate).
+				WithHelp("station tx rate in bits per second").
+				Build(),
+			metrics.NewPropertyGaugeMetric(staPrefix, "rx-rate", staLabelNames...).
+				WithConverter(metricFromWifiRate).
+				WithHelp("station rx rate in bits per second").
+				Build(),
+			metrics.NewPropertyGaugeMetric(staPrefix, "uptime", staLabelNames...).
+				WithConverter(convert.MetricFromDuration).
+				Build(),
+			metrics.NewPropertyRxTxMetric(staPrefix, "bytes", staLabelNames...).Build(),
+			metrics.NewPropertyRxTxMetric(staPrefix, "packets", staLabelNames...).Build(),
+		},
+		remoteCap: metrics.NewPropertyConstMetric(prefix, "state", capLabelNames...).
+			WithName("remote_cap_state").
+			WithHelp("CAPsMAN remote cap provisioning state").
+			Build(),
+		channel: metrics.Description(prefix, "interface_channel", "WiFi interface channel",
+			metrics.LabelDevName, metrics.LabelDevAddress, metrics.LabelInterface, "band",
+			"frequency", "standard", "width"),
+		frequency: metrics.Description(prefix, "interface_frequency", "WiFi interface frequency",
+			metrics.LabelDevName, metrics.LabelDevAddress, metrics.LabelInterface),
+		clients: metrics.Description(prefix, "interface_clients", "number of clients registered on interface",
+			metrics.LabelDevName, metrics.LabelDevAddress, metrics.LabelInterface),
+	}
+}
+
+func (c *wifiCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.radio.Describe(ch)
+	c.stations.Describe(ch)
+	c.remoteCap.Describe(ch)
+	ch <- c.channel
+	ch <- c.frequency
+	ch <- c.clients
+}
+
+func (c *wifiCollector) Collect(ctx *metrics.CollectorContext) error {
+	menu, err := c.menu(ctx)
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(
+		c.collectInterfaces(ctx, menu),
+		c.collectStations(ctx, menu),
+		c.collectRemoteCaps(ctx, menu),
+	)
+}
+
+// menu select api menu according to installed packages: `wifiwave2` package use
+// /interface/wifiwave2; since 7.13 (`wifi-qcom`, `wifi-qcom-ac` or CAPsMAN only) menu is /interface/wifi.
+func (c *wifiCollector) menu(ctx *metrics.CollectorContext) (string, error) {
+	if ctx.Device.FirmwareVersion.Major < 7 { //nolint:mnd
+		return "", NotSupportedError("wifi")
+	}
+
+	reply, err := ctx.Client.Run("/system/package/print", "?disabled=false", "=.proplist=name")
+	if err != nil {
+		return "", fmt.Errorf("fetch package error: %w", err)
+	}
+
+	for _, name := range convert.ExtractPropertyFromReplay(reply, "name") {
+		switch name {
+		case "wifiwave2":
+			return "/interface/wifiwave2", nil
+		case "wifi-qcom", "wifi-qcom-ac":
+			return "/interface/wifi", nil
+		}
+	}
+
+	if ctx.Device.FirmwareVersion.Compare(7, 13, 0) >= 0 { //nolint:mnd
+		return "/interface/wifi", nil
+	}
+
+	return "", NotSupportedError("wifi")
+}
+
+func (c *wifiCollector) collectInterfaces(ctx *metrics.CollectorContext, menu string) error {
+	reply, err := ctx.Client.Run(menu+"/print", "?disabled=false", "=.proplist=name,running,channel.band")
+	if err != nil {
+		return fmt.Errorf("fetch wifi interfaces error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		iface := re.Map["name"]
+		lctx := ctx.WithLabels(iface)
+
+		if err := c.radio.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect wifi %s error: %w", iface, err))
+		}
+
+		if re.Map["running"] != "true" {
+			continue
+		}
+
+		if err := c.collectMonitor(ctx, menu, iface, re.Map["channel.band"]); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (c *wifiCollector) collectMonitor(ctx *metrics.CollectorContext, menu, iface, band string) error {
+	reply, err := ctx.Client.Run(menu+"/monitor", "=numbers="+iface, "=once=", "=.proplist=channel")
+	if err != nil {
+		return fmt.Errorf("fetch wifi monitor for %s error: %w", iface, err)
+	}
+
+	if len(reply.Re) == 0 {
+		return nil
+	}
+
+	channel := reply.Re[0].Map["channel"]
+	if channel == "" {
+		return nil
+	}
+
+	freq, standard, width := parseWifiChannel(channel)
+
+	ctx.Ch <- prometheus.MustNewConstMetric(c.channel, prometheus.GaugeValue, 1,
+		ctx.Device.Name, ctx.Device.Address, iface, band, freq, standard, width)
+
+	value, err := strconv.ParseFloat(freq, 64)
+	if err != nil {
+		return fmt.Errorf("collect channel for %s parse %v error: %w", iface, freq, err)
+	}
+
+	ctx.Ch <- prometheus.MustNewConstMetric(c.frequency, prometheus.GaugeValue, value,
+		ctx.Device.Name, ctx.Device.Address, iface)
+
+	return nil
+}
+
+func (c *wifiCollector) collectStations(ctx *metrics.CollectorContext, menu string) error {
+	reply, err := ctx.Client.Run(menu+"/registration-table/print",
+		"=.proplist=interface,mac-address,ssid,signal,tx-rate,rx-rate,uptime,bytes,packets")
+	if err != nil {
+		return fmt.Errorf("fetch wifi registration table error: %w", err)
+	}
+
+	for iface, count := range metrics.CountByProperty(reply.Re, "interface") {
+		ctx.Ch <- prometheus.MustNewConstMetric(c.clients, prometheus.GaugeValue, float64(count),
+			ctx.Device.Name, ctx.Device.Address, iface)
+	}
+
+	// do not export metrics for each station if not configured
+	if !ctx.FeatureCfg.BoolValue("details", false) {
+		return nil
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "interface", "mac-address", "ssid")
+
+		if err := c.stations.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect wifi station %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *wifiCollector) collectRemoteCaps(ctx *metrics.CollectorContext, menu string) error {
+	reply, err := ctx.Client.Run(menu+"/capsman/remote-cap/print",
+		"=.proplist=identity,address,board-name,version,state")
+	if err != nil {
+		return fmt.Errorf("fetch wifi remote-cap error: %w", err)
+	}
+
+	var errs error
+
+	for _, re := range reply.Re {
+		lctx := ctx.WithLabelsFromMap(re.Map, "identity", "address", "board-name", "version", "state")
+
+		if err := c.remoteCap.Collect(re.Map, &lctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("collect wifi remote-cap %v error: %w", re, err))
+		}
+	}
+
+	return errs
+}
+
+// metricFromWifiRate parse rate in form like "866.7Mbps-80MHz/2S/SGI" into bits per second.
+func metricFromWifiRate(value string) (float64, error) {
+	rate, _, _ := strings.Cut(value, "-")
+
+	mult := 1.0
+
+	switch {
+	case strings.HasSuffix(rate, "Gbps"):
+		mult = 1e9
+		rate = strings.TrimSuffix(rate, "Gbps")
+	case strings.HasSuffix(rate, "Mbps"):
+		mult = 1e6
+		rate = strings.TrimSuffix(rate, "Mbps")
+	case strings.HasSuffix(rate, "kbps"):
+		mult = 1e3
+		rate = strings.TrimSuffix(rate, "kbps")
+	case strings.HasSuffix(rate, "bps"):
+		rate = strings.TrimSuffix(rate, "bps")
+	}
+
+	v, err := strconv.ParseFloat(rate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse rate %q error: %w", value, err)
+	}
+
+	return v * mult, nil
+}
+
+// parseWifiChannel split channel in form <frequency>/<standard>/<width>, i.e. "5180/ax/Ceee" into
+// parts; width is converted to MHz ("Ceee" - control and 3 extension 20MHz channels = 80MHz).
+func parseWifiChannel(channel string) (string, string, string) {
+	freq, rest, _ := strings.Cut(channel, "/")
+	standard, width, _ := strings.Cut(rest, "/")
+
+	if width != "" && strings.Trim(width, "Ce") == "" {
+		width = strconv.Itoa(len(width)*20) + "MHz" //nolint:mnd
+	}
+
+	return freq, standard, width
+}
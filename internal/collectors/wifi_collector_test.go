@@ -0,0 +1,29 @@
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWifiChannel(t *testing.T) {
+	tests := []struct {
+		channel, freq, standard, width string
+	}{
+		{"5180/ax/Ceee", "5180", "ax", "80MHz"},
+		{"2412/n/Ce", "2412", "n", "40MHz"},
+		{"2412/g", "2412", "g", ""},
+		{"5500/ac/eCee", "5500", "ac", "80MHz"},
+		{"5955/ax/unknown", "5955", "ax", "unknown"},
+		{"5180", "5180", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			freq, standard, width := parseWifiChannel(tt.channel)
+			assert.Equal(t, tt.freq, freq)
+			assert.Equal(t, tt.standard, standard)
+			assert.Equal(t, tt.width, width)
+		})
+	}
+}
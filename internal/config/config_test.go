@@ -2,9 +2,14 @@ package config
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
@@ -12,6 +17,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestShouldParse(t *testing.T) {
@@ -308,3 +314,341 @@ func TestFirmwareVersionCompare(t *testing.T) {
 		})
 	}
 }
+
+func TestUnknownField(t *testing.T) {
+	_, err := Load(strings.NewReader(`
+devices:
+  - name: dev1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+    pasword: typo
+`), nil)
+	require.Error(t, err)
+}
+
+func TestIncludes(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.Mkdir(confd, 0o755))
+
+	writeFile(t, filepath.Join(dir, "main.yml"), `
+include:
+  - conf.d/*.yml
+devices:
+  - name: main1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+features:
+  dhcp: true
+`)
+	writeFile(t, filepath.Join(confd, "team1.yml"), `
+devices:
+  - name: team1dev
+    address: 192.168.2.1
+    user: foo
+    password: bar
+    profile: team1
+profiles:
+  team1:
+    firmware: true
+`)
+	writeFile(t, filepath.Join(confd, "team2.yml"), `
+devices:
+  - name: team2dev
+    address: 192.168.3.1
+    user: foo
+    password: bar
+    profile: team1
+`)
+
+	c, err := LoadFile(filepath.Join(dir, "main.yml"), nil)
+	require.NoError(t, err)
+
+	require.Len(t, c.Devices, 3)
+	assert.Equal(t, "main1", c.Devices[0].Name)
+	assert.Equal(t, "team1dev", c.Devices[1].Name)
+	assert.Equal(t, "team2dev", c.Devices[2].Name)
+
+	names := c.DeviceFeatures("team2dev").FeatureNames()
+	sort.Strings(names)
+	assert.Equal(t, []string{"firmware", "resource"}, names)
+
+	t.Run("duplicated device", func(t *testing.T) {
+		writeFile(t, filepath.Join(confd, "team3.yml"), `
+devices:
+  - name: team1dev
+    address: 192.168.4.1
+    user: foo
+    password: bar
+`)
+		defer os.Remove(filepath.Join(confd, "team3.yml"))
+
+		_, err := LoadFile(filepath.Join(dir, "main.yml"), nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, DuplicatedDeviceError("team1dev"))
+		assert.ErrorContains(t, err, filepath.Join(confd, "team1.yml"))
+		assert.ErrorContains(t, err, filepath.Join(confd, "team3.yml"))
+	})
+
+	t.Run("duplicated profile", func(t *testing.T) {
+		writeFile(t, filepath.Join(confd, "team3.yml"), `
+profiles:
+  team1:
+    health: true
+`)
+		defer os.Remove(filepath.Join(confd, "team3.yml"))
+
+		_, err := LoadFile(filepath.Join(dir, "main.yml"), nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, DuplicatedProfileError("team1"))
+	})
+
+	t.Run("unsupported section", func(t *testing.T) {
+		writeFile(t, filepath.Join(confd, "team3.yml"), `
+features:
+  health: true
+`)
+		defer os.Remove(filepath.Join(confd, "team3.yml"))
+
+		_, err := LoadFile(filepath.Join(dir, "main.yml"), nil)
+		require.Error(t, err)
+	})
+}
+
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+}
+
+func TestRemoteWrite(t *testing.T) {
+	c, err := Load(strings.NewReader(`
+devices:
+  - name: dev1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+remote_write:
+  url: https://prometheus.example.com/api/v1/write
+  username: user
+  password: pass
+`), nil)
+	require.NoError(t, err)
+	require.NotNil(t, c.RemoteWrite)
+	assert.Equal(t, DefaultPushInterval, c.RemoteWrite.Interval)
+	assert.Equal(t, DefaultPushRetries, *c.RemoteWrite.MaxRetries)
+	assert.Equal(t, DefaultPushBufferSize, c.RemoteWrite.BufferSize)
+
+	c, err = Load(strings.NewReader(`
+devices: []
+remote_write:
+  url: https://prometheus.example.com/api/v1/write
+  max_retries: 0
+`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, *c.RemoteWrite.MaxRetries)
+
+	_, err = Load(strings.NewReader(`
+devices: []
+remote_write:
+  url: ftp://prometheus.example.com/
+  password: pass
+`), nil)
+
+	var ife InvalidFieldValueError
+	require.ErrorAs(t, err, &ife)
+
+	var mfe MissingFieldError
+	require.ErrorAs(t, err, &mfe)
+}
+
+func TestSharding(t *testing.T) {
+	const count = 4
+
+	names := make([]string, 0, 1000)
+	for i := range 1000 {
+		names = append(names, fmt.Sprintf("router-%d", i))
+	}
+
+	shards := make([]Sharding, count)
+	perShard := make([]int, count)
+
+	for i := range shards {
+		shards[i] = Sharding{Index: i, Count: count}
+	}
+
+	for _, name := range names {
+		owners := 0
+
+		for i, s := range shards {
+			if s.Owns(name) {
+				owners++
+				perShard[i]++
+			}
+		}
+
+		// each device is handled by exactly one shard
+		assert.Equal(t, 1, owners, name)
+	}
+
+	for _, n := range perShard {
+		assert.InDelta(t, len(names)/count, n, 60)
+	}
+
+	// adding shard move only part of devices
+	moved := 0
+
+	for _, name := range names {
+		if ShardFor(name, count) != ShardFor(name, count+1) {
+			moved++
+		}
+	}
+
+	assert.Less(t, moved, len(names)/2)
+
+	var nilSharding *Sharding
+	assert.True(t, nilSharding.Owns("any"))
+
+	_, err := Load(strings.NewReader(`
+devices: []
+sharding:
+  index: 2
+  count: 2
+`), nil)
+
+	var ife InvalidFieldValueError
+	require.ErrorAs(t, err, &ife)
+}
+
+func TestFeatureConfMaps(t *testing.T) {
+	feat := FeatureConf{
+		"targets": []any{
+			map[string]any{"address": " 1.1.1.1 ", "count": 5},
+			map[string]any{"address": "8.8.8.8", "vrf": "vrf1"},
+		},
+		"invalid": []any{"1.1.1.1"},
+		"scalar":  "abc",
+	}
+
+	targets, err := feat.Maps("targets")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"address": "1.1.1.1", "count": "5"},
+		{"address": "8.8.8.8", "vrf": "vrf1"},
+	}, targets)
+
+	targets, err = feat.Maps("missing")
+	require.NoError(t, err)
+	assert.Nil(t, targets)
+
+	_, err = feat.Maps("invalid")
+	require.ErrorIs(t, err, ErrInvalidValueType)
+
+	_, err = feat.Maps("scalar")
+	require.ErrorIs(t, err, ErrInvalidValueType)
+}
+
+func TestFeatureConfIntValue(t *testing.T) {
+	feat := FeatureConf{"limit": 100, "invalid": "abc"}
+
+	assert.Equal(t, 100, feat.IntValue("limit", 10))
+	assert.Equal(t, 10, feat.IntValue("invalid", 10))
+	assert.Equal(t, 10, feat.IntValue("missing", 10))
+}
+
+func TestSSHAuthLoaded(t *testing.T) {
+	dir := t.TempDir()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, os.WriteFile(knownHosts, nil, 0o600))
+
+	cfgTmpl := `
+devices:
+  - name: dev1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+    ssh_jump: tunnel@bastion
+    ssh_key: %[1]s
+    ssh_known_hosts: %[2]s
+  - name: dev2
+    address: 192.168.1.2
+    user: foo
+    password: bar
+    ssh_jump: tunnel@bastion
+    ssh_key: %[1]s
+    ssh_known_hosts: %[2]s
+  - name: dev3
+    address: 192.168.1.3
+    user: foo
+    password: bar
+`
+
+	c, err := Load(strings.NewReader(fmt.Sprintf(cfgTmpl, keyFile, knownHosts)), nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, c.Devices[0].SSHAuth)
+	assert.NotNil(t, c.Devices[0].SSHAuth.Signer)
+	// shared files are loaded once
+	assert.Same(t, c.Devices[0].SSHAuth, c.Devices[1].SSHAuth)
+	assert.Nil(t, c.Devices[2].SSHAuth)
+
+	_, err = Load(strings.NewReader(fmt.Sprintf(cfgTmpl, filepath.Join(dir, "missing"), knownHosts)), nil)
+	require.Error(t, err)
+}
+
+func TestTLSConfigLoaded(t *testing.T) {
+	dir := t.TempDir()
+
+	invalidCA := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(invalidCA, []byte("not a certificate"), 0o600))
+
+	c, err := Load(strings.NewReader(`
+devices:
+  - name: dev1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+    tls: true
+    min_tls_version: "1.2"
+  - name: dev2
+    address: 192.168.1.2
+    user: foo
+    password: bar
+    tls: true
+    min_tls_version: "1.2"
+  - name: dev3
+    address: 192.168.1.3
+    user: foo
+    password: bar
+`), nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, c.Devices[0].TLSConfig)
+	assert.Equal(t, uint16(tls.VersionTLS12), c.Devices[0].TLSConfig.MinVersion)
+	assert.Same(t, c.Devices[0].TLSConfig, c.Devices[1].TLSConfig)
+	assert.Nil(t, c.Devices[2].TLSConfig)
+
+	for _, caFile := range []string{invalidCA, filepath.Join(dir, "missing.pem")} {
+		_, err = Load(strings.NewReader(`
+devices:
+  - name: dev1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+    tls: true
+    ca_file: `+caFile), nil)
+		require.Error(t, err, caFile)
+	}
+}
@@ -0,0 +1,269 @@
+package config
+
+//
+// inventory.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	InventoryFormatCSV    = "csv"
+	InventoryFormatNetBox = "netbox"
+)
+
+var ErrInvalidInventory = errors.New("invalid inventory")
+
+// InventorySource define external file with list of devices.
+type InventorySource struct {
+	// Defaults are used for all devices loaded from source; i.e. user, password.
+	Defaults Device `yaml:"defaults,omitempty"`
+	// Mapping define source of device fields; device field -> column name (csv) or
+	// dotted path to value (netbox).
+	Mapping map[string]string `yaml:"mapping,omitempty"`
+	// Labels define additional labels for devices; label name -> column name (csv)
+	// or dotted path to value (netbox).
+	Labels map[string]string `yaml:"labels,omitempty"`
+	File   string            `yaml:"file"`
+	// Format of file: csv (default) or netbox.
+	Format string `yaml:"format,omitempty"`
+}
+
+// inventoryFields is list of device fields that can be loaded from inventory.
+var inventoryFields = []string{"name", "address", "port", "profile", "user", "password"}
+
+func (i *InventorySource) mapping() map[string]string {
+	mapping := map[string]string{
+		"name":    "name",
+		"address": "address",
+		"port":    "port",
+		"profile": "profile",
+	}
+
+	if i.Format == InventoryFormatNetBox {
+		mapping = map[string]string{
+			"name":    "name",
+			"address": "primary_ip.address",
+		}
+	}
+
+	maps.Copy(mapping, i.Mapping)
+
+	return mapping
+}
+
+func (i *InventorySource) labels() map[string]string {
+	if i.Labels != nil {
+		return i.Labels
+	}
+
+	if i.Format == InventoryFormatNetBox {
+		return map[string]string{"site": "site.slug"}
+	}
+
+	return map[string]string{"site": "site"}
+}
+
+func (i *InventorySource) validate() error {
+	var errs error
+
+	if i.File == "" {
+		errs = errors.Join(errs, MissingFieldError("file"))
+	}
+
+	if i.Format != "" && i.Format != InventoryFormatCSV && i.Format != InventoryFormatNetBox {
+		errs = errors.Join(errs, InvalidFieldValueError{"format", i.Format})
+	}
+
+	for field := range i.Mapping {
+		if !slices.Contains(inventoryFields, field) {
+			errs = errors.Join(errs, InvalidFieldValueError{"mapping", field})
+		}
+	}
+
+	return errs
+}
+
+// Load devices from inventory file. Relative path is resolved against `baseDir`.
+func (i *InventorySource) Load(baseDir string) ([]Device, error) {
+	if err := i.validate(); err != nil {
+		return nil, err
+	}
+
+	filename := i.File
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(baseDir, filename)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open file error: %w", err)
+	}
+	defer file.Close()
+
+	var records []map[string]string
+
+	if i.Format == InventoryFormatNetBox {
+		records, err = readNetBoxRecords(file, i.mapping(), i.labels())
+	} else {
+		records, err = readCSVRecords(file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read %s error: %w", filename, err)
+	}
+
+	devices := make([]Device, 0, len(records))
+	for _, rec := range records {
+		devices = append(devices, i.newDevice(rec))
+	}
+
+	return devices, nil
+}
+
+func (i *InventorySource) newDevice(record map[string]string) Device {
+	dev := i.Defaults
+	dev.Labels = maps.Clone(i.Defaults.Labels)
+
+	for field, key := range i.mapping() {
+		value := strings.TrimSpace(record[key])
+		if value == "" {
+			continue
+		}
+
+		switch field {
+		case "name":
+			dev.Name = value
+		case "address":
+			// NetBox keep address with prefix length
+			dev.Address, _, _ = strings.Cut(value, "/")
+		case "port":
+			dev.Port = value
+		case "profile":
+			dev.Profile = value
+		case "user":
+			dev.User = value
+		case "password":
+			dev.Password = value
+		}
+	}
+
+	for label, key := range i.labels() {
+		if value := strings.TrimSpace(record[key]); value != "" {
+			if dev.Labels == nil {
+				dev.Labels = make(map[string]string)
+			}
+
+			dev.Labels[label] = value
+		}
+	}
+
+	return dev
+}
+
+// readCSVRecords load records from csv file; first row must contain columns names.
+func readCSVRecords(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv error: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	for idx, col := range header {
+		header[idx] = strings.TrimSpace(col)
+	}
+
+	records := make([]map[string]string, 0, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		rec := make(map[string]string, len(header))
+		for idx, col := range header {
+			rec[col] = row[idx]
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// readNetBoxRecords load devices from NetBox `/api/dcim/devices` export. Export may be
+// full api response (with `results`) or just list of devices. Returned records contains
+// only values for given `mapping` and `labels` paths.
+func readNetBoxRecords(r io.Reader, mapping, labels map[string]string) ([]map[string]string, error) {
+	var data any
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("parse json error: %w", err)
+	}
+
+	if resp, ok := data.(map[string]any); ok {
+		data = resp["results"]
+	}
+
+	items, ok := data.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected list of devices", ErrInvalidInventory)
+	}
+
+	paths := make([]string, 0, len(mapping)+len(labels))
+	paths = append(paths, slices.Collect(maps.Values(mapping))...)
+	paths = append(paths, slices.Collect(maps.Values(labels))...)
+
+	records := make([]map[string]string, 0, len(items))
+
+	for _, item := range items {
+		rec := make(map[string]string, len(paths))
+		for _, path := range paths {
+			rec[path] = jsonPathValue(item, path)
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// jsonPathValue get value from `data` for dotted `path` and convert it to string.
+func jsonPathValue(data any, path string) string {
+	for key := range strings.SplitSeq(path, ".") {
+		obj, ok := data.(map[string]any)
+		if !ok {
+			return ""
+		}
+
+		data = obj[key]
+	}
+
+	switch v := data.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return ""
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryCSV(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "devices.csv"), `name,address,port,profile,site
+# comment
+dev1,192.168.1.1,,,waw
+dev2, 192.168.1.2,8729,basic,krk
+`)
+
+	src := InventorySource{
+		File:     "devices.csv",
+		Defaults: Device{User: "ro", Password: "pass", Labels: map[string]string{"team": "noc"}},
+	}
+
+	devices, err := src.Load(dir)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+
+	assert.Equal(t, "dev1", devices[0].Name)
+	assert.Equal(t, "192.168.1.1", devices[0].Address)
+	assert.Empty(t, devices[0].Port)
+	assert.Empty(t, devices[0].Profile)
+	assert.Equal(t, "ro", devices[0].User)
+	assert.Equal(t, "pass", devices[0].Password)
+	assert.Equal(t, map[string]string{"site": "waw", "team": "noc"}, devices[0].Labels)
+
+	assert.Equal(t, "dev2", devices[1].Name)
+	assert.Equal(t, "192.168.1.2", devices[1].Address)
+	assert.Equal(t, "8729", devices[1].Port)
+	assert.Equal(t, "basic", devices[1].Profile)
+	assert.Equal(t, map[string]string{"site": "krk", "team": "noc"}, devices[1].Labels)
+
+	// defaults are not modified
+	assert.Equal(t, map[string]string{"team": "noc"}, src.Defaults.Labels)
+}
+
+func TestInventoryNetBox(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "devices.json"), `{
+  "count": 2,
+  "results": [
+    {
+      "id": 1,
+      "name": "rtr1",
+      "primary_ip": {"id": 10, "address": "10.0.0.1/24"},
+      "site": {"id": 3, "slug": "waw", "name": "Warsaw"},
+      "role": {"slug": "core"},
+      "custom_fields": {"mikrotik_profile": "basic"}
+    },
+    {
+      "id": 2,
+      "name": "rtr2",
+      "primary_ip": {"id": 11, "address": "10.0.0.2/24"},
+      "site": {"id": 3, "slug": "krk", "name": "Krakow"},
+      "role": null,
+      "custom_fields": {"mikrotik_profile": null}
+    }
+  ]
+}`)
+
+	src := InventorySource{
+		File:     filepath.Join(dir, "devices.json"),
+		Format:   InventoryFormatNetBox,
+		Mapping:  map[string]string{"profile": "custom_fields.mikrotik_profile"},
+		Labels:   map[string]string{"site": "site.slug", "role": "role.slug"},
+		Defaults: Device{User: "ro", Password: "pass"},
+	}
+
+	devices, err := src.Load("")
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+
+	assert.Equal(t, "rtr1", devices[0].Name)
+	assert.Equal(t, "10.0.0.1", devices[0].Address)
+	assert.Equal(t, "basic", devices[0].Profile)
+	assert.Equal(t, map[string]string{"site": "waw", "role": "core"}, devices[0].Labels)
+
+	assert.Equal(t, "rtr2", devices[1].Name)
+	assert.Equal(t, "10.0.0.2", devices[1].Address)
+	assert.Empty(t, devices[1].Profile)
+	assert.Equal(t, map[string]string{"site": "krk"}, devices[1].Labels)
+}
+
+func TestInventoryConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "devices.csv"), "name,address,site\ndev1,192.168.1.1,waw\n")
+	writeFile(t, filepath.Join(dir, "main.yml"), `
+devices:
+  - name: main1
+    address: 192.168.1.1
+    user: foo
+    password: bar
+    labels:
+      rack: r1
+inventory:
+  - file: devices.csv
+    defaults:
+      user: ro
+      password: ro
+`)
+
+	c, err := LoadFile(filepath.Join(dir, "main.yml"), nil)
+	require.NoError(t, err)
+	require.Len(t, c.Devices, 2)
+
+	dev := c.FindDevice("dev1")
+	assert.Equal(t, "192.168.1.1", dev.Address)
+	assert.Equal(t, "ro", dev.User)
+	assert.Equal(t, []string{"rack", "site"}, c.DeviceLabelNames())
+
+	t.Run("invalid config", func(t *testing.T) {
+		config := []byte(`
+inventory:
+  - format: xml
+    mapping:
+      serial: serial
+`)
+
+		_, err := Load(bytes.NewReader(config), nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, MissingFieldError("file"))
+		assert.ErrorIs(t, err, InvalidFieldValueError{"format", "xml"})
+		assert.ErrorIs(t, err, InvalidFieldValueError{"mapping", "serial"})
+	})
+}
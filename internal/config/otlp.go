@@ -0,0 +1,46 @@
+package config
+
+//
+// otlp.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"errors"
+	"net/url"
+)
+
+// OTLP configure pushing metrics to OpenTelemetry collector via OTLP/HTTP.
+type OTLP struct {
+	// URL is full url of metrics endpoint, e.g. http://localhost:4318/v1/metrics.
+	URL string `yaml:"url"`
+	// Headers are additional http headers added to each request (e.g. authorization).
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Interval between collecting and pushing metrics, in seconds.
+	Interval int `yaml:"interval,omitempty"`
+	// Timeout of single request, in seconds.
+	Timeout int `yaml:"timeout,omitempty"`
+}
+
+func (o *OTLP) validate() error {
+	var errs error
+
+	if o.URL == "" {
+		errs = errors.Join(errs, MissingFieldError("otlp.url"))
+	} else if u, err := url.Parse(o.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = errors.Join(errs, InvalidFieldValueError{"otlp.url", o.URL})
+	}
+
+	return errs
+}
+
+func (o *OTLP) fix() {
+	if o.Interval <= 0 {
+		o.Interval = DefaultPushInterval
+	}
+
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultPushTimeout
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+//
+// remote_write.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"errors"
+	"net/url"
+)
+
+const (
+	// DefaultPushInterval is default interval (in seconds) between pushes of metrics.
+	DefaultPushInterval = 60
+	// DefaultPushTimeout is default timeout (in seconds) of single push request.
+	DefaultPushTimeout = 30
+	// DefaultPushRetries is default number of retries of failed push request.
+	DefaultPushRetries = 3
+	// DefaultPushBufferSize is default number of pending batches kept when endpoint is unavailable.
+	DefaultPushBufferSize = 60
+)
+
+// RemoteWrite configure pushing metrics to Prometheus remote_write endpoint.
+type RemoteWrite struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Interval between collecting and pushing metrics, in seconds.
+	Interval int `yaml:"interval,omitempty"`
+	// Timeout of single request, in seconds.
+	Timeout int `yaml:"timeout,omitempty"`
+	// MaxRetries is number of retries of failed request before batch is left in buffer;
+	// nil means default, 0 - no retries.
+	MaxRetries *int `yaml:"max_retries,omitempty"`
+	// BufferSize is maximal number of batches kept in memory when endpoint is unavailable;
+	// the oldest batches are dropped first.
+	BufferSize int `yaml:"buffer_size,omitempty"`
+}
+
+func (r *RemoteWrite) validate() error {
+	var errs error
+
+	if r.URL == "" {
+		errs = errors.Join(errs, MissingFieldError("remote_write.url"))
+	} else if u, err := url.Parse(r.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = errors.Join(errs, InvalidFieldValueError{"remote_write.url", r.URL})
+	}
+
+	if r.Password != "" && r.Username == "" {
+		errs = errors.Join(errs, MissingFieldError("remote_write.username"))
+	}
+
+	return errs
+}
+
+func (r *RemoteWrite) fix() {
+	if r.Interval <= 0 {
+		r.Interval = DefaultPushInterval
+	}
+
+	if r.Timeout <= 0 {
+		r.Timeout = DefaultPushTimeout
+	}
+
+	if r.MaxRetries == nil {
+		r.MaxRetries = new(DefaultPushRetries)
+	} else if *r.MaxRetries < 0 {
+		r.MaxRetries = new(0)
+	}
+
+	if r.BufferSize <= 0 {
+		r.BufferSize = DefaultPushBufferSize
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+//
+// sharding.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"errors"
+	"hash/fnv"
+	"strconv"
+)
+
+// Sharding configure splitting devices between exporter instances. Each instance collect
+// only devices which name (or srv target) is mapped to its shard.
+type Sharding struct {
+	// Index is index of this instance shard (0..Count-1).
+	Index int `yaml:"index"`
+	// Count is total number of shards.
+	Count int `yaml:"count"`
+}
+
+func (s *Sharding) validate() error {
+	var errs error
+
+	if s.Count < 1 {
+		errs = errors.Join(errs, InvalidFieldValueError{"sharding.count", strconv.Itoa(s.Count)})
+	}
+
+	if s.Index < 0 || (s.Count > 0 && s.Index >= s.Count) {
+		errs = errors.Join(errs, InvalidFieldValueError{"sharding.index", strconv.Itoa(s.Index)})
+	}
+
+	return errs
+}
+
+// Owns return true when device `name` belongs to this shard. Nil Sharding owns all devices.
+func (s *Sharding) Owns(name string) bool {
+	if s == nil || s.Count <= 1 {
+		return true
+	}
+
+	return ShardFor(name, s.Count) == s.Index
+}
+
+// ShardFor return shard for `name` using jump consistent hash of fnv-1a hash of name;
+// when shards are added only ~1/count of devices are moved.
+func ShardFor(name string, count int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	key := h.Sum64()
+
+	var b, j int64 = -1, 0
+
+	for j < int64(count) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1))) //nolint:mnd
+	}
+
+	return int(b)
+}
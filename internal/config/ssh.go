@@ -0,0 +1,85 @@
+package config
+
+//
+// ssh.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHAuth is parsed private key and known hosts used to connect to ssh jump host.
+type SSHAuth struct {
+	Signer          ssh.Signer
+	HostKeyCallback ssh.HostKeyCallback
+}
+
+// LoadSSHAuth read private key from `keyFile` and known hosts from `knownHostsFile`
+// (default ~/.ssh/known_hosts).
+func LoadSSHAuth(keyFile, knownHostsFile string) (*SSHAuth, error) {
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ssh key error: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh key error: %w", err)
+	}
+
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("find home directory error: %w", err)
+		}
+
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("load known hosts error: %w", err)
+	}
+
+	return &SSHAuth{signer, hostKeyCallback}, nil
+}
+
+// loadSSHAuth load keys and known hosts for devices connected via ssh jump host; files shared
+// by many devices are loaded once.
+func (c *Config) loadSSHAuth() error {
+	type files struct{ key, knownHosts string }
+
+	loaded := make(map[files]*SSHAuth)
+
+	for idx := range c.Devices {
+		d := &c.Devices[idx]
+		if d.SSHJump == "" {
+			continue
+		}
+
+		f := files{d.SSHKey, d.SSHKnownHosts}
+
+		auth, ok := loaded[f]
+		if !ok {
+			var err error
+
+			auth, err = LoadSSHAuth(d.SSHKey, d.SSHKnownHosts)
+			if err != nil {
+				return fmt.Errorf("invalid device %d (%s) configuration: %w", idx, d.Name, err)
+			}
+
+			loaded[f] = auth
+		}
+
+		d.SSHAuth = auth
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+//
+// tls.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"mikrotik-exporter/routeros"
+)
+
+// TLSOptions return options for creating tls configuration for device.
+func (d *Device) TLSOptions() routeros.TLSOptions {
+	return routeros.TLSOptions{
+		CAFile:     d.CAFile,
+		CertFile:   d.CertFile,
+		KeyFile:    d.KeyFile,
+		ServerName: d.ServerName,
+		MinVersion: d.MinTLSVersion,
+		Insecure:   d.Insecure,
+	}
+}
+
+// loadTLSConfig load CA and client certificates for devices connected via TLS; devices with
+// the same settings share configuration.
+func (c *Config) loadTLSConfig() error {
+	loaded := make(map[routeros.TLSOptions]*tls.Config)
+
+	for idx := range c.Devices {
+		d := &c.Devices[idx]
+		if !d.TLS {
+			continue
+		}
+
+		opts := d.TLSOptions()
+
+		tlsConfig, ok := loaded[opts]
+		if !ok {
+			var err error
+
+			tlsConfig, err = routeros.NewTLSConfig(opts)
+			if err != nil {
+				return fmt.Errorf("invalid device %d (%s) configuration: %w", idx, d.Name, err)
+			}
+
+			loaded[opts] = tlsConfig
+		}
+
+		d.TLSConfig = tlsConfig
+	}
+
+	return nil
+}
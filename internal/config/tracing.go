@@ -0,0 +1,62 @@
+package config
+
+//
+// tracing.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"errors"
+	"net/url"
+)
+
+const (
+	TracingExporterOTLP = "otlp"
+	TracingExporterFile = "file"
+)
+
+// Tracing configure tracing of scrapes, collectors and API commands.
+type Tracing struct {
+	// Exporter is `otlp` (default) or `file`.
+	Exporter string `yaml:"exporter,omitempty"`
+	// URL is full url of OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces.
+	URL string `yaml:"url,omitempty"`
+	// Headers are additional http headers added to each request.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Timeout of single request, in seconds.
+	Timeout int `yaml:"timeout,omitempty"`
+	// File is name of file where spans are written as json lines.
+	File string `yaml:"file,omitempty"`
+}
+
+func (t *Tracing) validate() error {
+	var errs error
+
+	switch t.Exporter {
+	case "", TracingExporterOTLP:
+		if t.URL == "" {
+			errs = errors.Join(errs, MissingFieldError("tracing.url"))
+		} else if u, err := url.Parse(t.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = errors.Join(errs, InvalidFieldValueError{"tracing.url", t.URL})
+		}
+	case TracingExporterFile:
+		if t.File == "" {
+			errs = errors.Join(errs, MissingFieldError("tracing.file"))
+		}
+	default:
+		errs = errors.Join(errs, InvalidFieldValueError{"tracing.exporter", t.Exporter})
+	}
+
+	return errs
+}
+
+func (t *Tracing) fix() {
+	if t.Exporter == "" {
+		t.Exporter = TracingExporterOTLP
+	}
+
+	if t.Timeout <= 0 {
+		t.Timeout = DefaultPushTimeout
+	}
+}
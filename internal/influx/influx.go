@@ -0,0 +1,159 @@
+package influx
+
+//
+// influx.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mikrotik-exporter/internal/metrics"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ContentType is content type of line protocol response.
+const ContentType = "text/plain; charset=utf-8"
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+)
+
+type (
+	tag struct {
+		key   string
+		value string
+	}
+
+	field struct {
+		key   string
+		value float64
+	}
+
+	// point is one line: all fields of one entity (measurement + tags).
+	point struct {
+		measurement string
+		tags        []tag
+		fields      []field
+	}
+)
+
+func (p *point) key() string {
+	var b strings.Builder
+
+	b.WriteString(p.measurement)
+
+	for _, t := range p.tags {
+		b.WriteByte(0)
+		b.WriteString(t.key)
+		b.WriteByte(0)
+		b.WriteString(t.value)
+	}
+
+	return b.String()
+}
+
+// Write render metric families as Influx line protocol. Measurement is metric prefix, labels
+// become tags and values of metrics with the same measurement and tags are fields of one line.
+func Write(w io.Writer, mfs []*dto.MetricFamily, now time.Time) error {
+	points := make(map[string]*point)
+
+	for _, mf := range mfs {
+		measurement, fieldName := metrics.SplitMetricName(mf.GetName())
+
+		for _, m := range mf.GetMetric() {
+			var value float64
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				value = m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+
+			// line protocol not support NaN and Inf values
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+
+			p := &point{measurement: measurement}
+
+			for _, lp := range m.GetLabel() {
+				// empty tags are not allowed
+				if lp.GetValue() != "" {
+					p.tags = append(p.tags, tag{lp.GetName(), lp.GetValue()})
+				}
+			}
+
+			slices.SortFunc(p.tags, func(a, b tag) int { return strings.Compare(a.key, b.key) })
+
+			key := p.key()
+			if existing, ok := points[key]; ok {
+				p = existing
+			} else {
+				points[key] = p
+			}
+
+			p.fields = append(p.fields, field{fieldName, value})
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(points))
+
+	bw := bufio.NewWriter(w)
+	ts := strconv.FormatInt(now.UnixNano(), 10)
+
+	for _, k := range keys {
+		writePoint(bw, points[k], ts)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	return nil
+}
+
+func writePoint(w *bufio.Writer, p *point, ts string) {
+	_, _ = w.WriteString(measurementEscaper.Replace(p.measurement))
+
+	for _, t := range p.tags {
+		_ = w.WriteByte(',')
+		_, _ = w.WriteString(keyEscaper.Replace(t.key))
+		_ = w.WriteByte('=')
+		_, _ = w.WriteString(keyEscaper.Replace(t.value))
+	}
+
+	slices.SortFunc(p.fields, func(a, b field) int { return strings.Compare(a.key, b.key) })
+
+	for i, f := range p.fields {
+		if i == 0 {
+			_ = w.WriteByte(' ')
+		} else {
+			_ = w.WriteByte(',')
+		}
+
+		_, _ = w.WriteString(keyEscaper.Replace(f.key))
+		_ = w.WriteByte('=')
+		_, _ = w.WriteString(strconv.FormatFloat(f.value, 'f', -1, 64))
+	}
+
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(ts)
+	_ = w.WriteByte('\n')
+}
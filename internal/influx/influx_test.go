@@ -0,0 +1,41 @@
+package influx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"mikrotik-exporter/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	labels := []string{"dev_name", "dev_address", "name", "comment"}
+	rxDesc := metrics.Description("interface", "rx_byte", "rx", labels...)
+	txDesc := metrics.Description("interface", "tx_byte", "tx", labels...)
+	uptimeDesc := metrics.Description("system", "uptime", "uptime", "dev_name", "dev_address")
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(prometheus.CollectorFunc(func(ch chan<- prometheus.Metric) {
+		ch <- prometheus.MustNewConstMetric(rxDesc, prometheus.CounterValue, 100, "dev1", "10.0.0.1", "ether1", "")
+		ch <- prometheus.MustNewConstMetric(txDesc, prometheus.CounterValue, 200, "dev1", "10.0.0.1", "ether1", "")
+		ch <- prometheus.MustNewConstMetric(rxDesc, prometheus.CounterValue, 1.5, "dev1", "10.0.0.1",
+			"ether 2", "uplink,isp")
+		ch <- prometheus.MustNewConstMetric(uptimeDesc, prometheus.GaugeValue, 12345678901, "dev1", "10.0.0.1")
+	})))
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, mfs, time.Unix(1, 0)))
+
+	assert.Equal(t,
+		`interface,comment=uplink\,isp,dev_address=10.0.0.1,dev_name=dev1,name=ether\ 2 rx_byte=1.5 1000000000
+interface,dev_address=10.0.0.1,dev_name=dev1,name=ether1 rx_byte=100,tx_byte=200 1000000000
+system,dev_address=10.0.0.1,dev_name=dev1 uptime=12345678901 1000000000
+`, buf.String())
+}
@@ -23,6 +23,8 @@ type (
 func descriptionForPropertyNameHelpText(prefix, property string,
 	labelNames []string, helpText string,
 ) *prometheus.Desc {
+	registerPrefix(prefix)
+
 	return prometheus.NewDesc(
 		prometheus.BuildFQName(config.Namespace, prefix, MetricStringCleanup(property)),
 		helpText,
@@ -32,6 +34,8 @@ func descriptionForPropertyNameHelpText(prefix, property string,
 }
 
 func Description(prefix, name, helpText string, labelNames ...string) *prometheus.Desc {
+	registerPrefix(prefix)
+
 	return prometheus.NewDesc(
 		prometheus.BuildFQName(config.Namespace, prefix, MetricStringCleanup(name)),
 		helpText,
@@ -0,0 +1,59 @@
+package metrics
+
+//
+// prefixes.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"strings"
+	"sync"
+
+	"mikrotik-exporter/internal/config"
+)
+
+// knownPrefixes keep all prefixes used to create metrics descriptions.
+var (
+	knownPrefixes   = make(map[string]struct{})
+	knownPrefixesMu sync.RWMutex
+)
+
+func registerPrefix(prefix string) {
+	if prefix == "" {
+		return
+	}
+
+	knownPrefixesMu.Lock()
+	defer knownPrefixesMu.Unlock()
+
+	knownPrefixes[prefix] = struct{}{}
+}
+
+// SplitMetricName split full metric name into prefix (i.e. `interface`) and name of value
+// (i.e. `rx_byte`). Prefixes registered by metrics descriptions are preferred (the longest
+// match); for other metrics first part of the name is used as prefix.
+func SplitMetricName(name string) (string, string) {
+	name = strings.TrimPrefix(name, config.Namespace+"_")
+
+	knownPrefixesMu.RLock()
+	defer knownPrefixesMu.RUnlock()
+
+	prefix := ""
+
+	for p := range knownPrefixes {
+		if len(p) > len(prefix) && strings.HasPrefix(name, p+"_") {
+			prefix = p
+		}
+	}
+
+	if prefix != "" {
+		return prefix, name[len(prefix)+1:]
+	}
+
+	if prefix, field, ok := strings.Cut(name, "_"); ok {
+		return prefix, field
+	}
+
+	return name, "value"
+}
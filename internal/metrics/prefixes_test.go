@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMetricName(t *testing.T) {
+	registerPrefix("capsman")
+	registerPrefix("capsman_station")
+
+	tests := []struct {
+		name   string
+		prefix string
+		field  string
+	}{
+		{"mikrotik_capsman_station_rx_bytes", "capsman_station", "rx_bytes"},
+		{"mikrotik_capsman_provisioned", "capsman", "provisioned"},
+		{"mikrotik_scrape_device_success", "scrape", "device_success"},
+		{"mikrotik_up", "up", "value"},
+	}
+
+	for _, tc := range tests {
+		prefix, field := SplitMetricName(tc.name)
+		assert.Equal(t, tc.prefix, prefix, tc.name)
+		assert.Equal(t, tc.field, field, tc.name)
+	}
+}
@@ -0,0 +1,269 @@
+package otlp
+
+//
+// metrics.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"mikrotik-exporter/internal/collector"
+	"mikrotik-exporter/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// aggregationTemporalityCumulative is AGGREGATION_TEMPORALITY_CUMULATIVE.
+const aggregationTemporalityCumulative = 2
+
+type (
+	NumberDataPoint struct {
+		Attributes        []KeyValue `json:"attributes,omitempty"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+		TimeUnixNano      string     `json:"timeUnixNano"`
+		AsDouble          float64    `json:"asDouble"`
+	}
+
+	Gauge struct {
+		DataPoints []NumberDataPoint `json:"dataPoints"`
+	}
+
+	Sum struct {
+		DataPoints             []NumberDataPoint `json:"dataPoints"`
+		AggregationTemporality int               `json:"aggregationTemporality"`
+		IsMonotonic            bool              `json:"isMonotonic"`
+	}
+
+	Metric struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Unit        string `json:"unit,omitempty"`
+		Gauge       *Gauge `json:"gauge,omitempty"`
+		Sum         *Sum   `json:"sum,omitempty"`
+	}
+
+	ScopeMetrics struct {
+		Scope   InstrumentationScope `json:"scope"`
+		Metrics []Metric             `json:"metrics"`
+	}
+
+	ResourceMetrics struct {
+		Resource     Resource       `json:"resource"`
+		ScopeMetrics []ScopeMetrics `json:"scopeMetrics"`
+	}
+
+	ExportMetricsServiceRequest struct {
+		ResourceMetrics []ResourceMetrics `json:"resourceMetrics"`
+	}
+)
+
+// MetricsExporter periodically collect metrics from devices and push it to OTLP endpoint.
+type MetricsExporter struct {
+	cfg       *config.OTLP
+	collector collector.Collector
+	gatherer  prometheus.Gatherer
+	client    *http.Client
+	// startTime is start time of cumulative sums.
+	startTime time.Time
+	// skipped keep names of families with unsupported type (histograms, summaries) that were
+	// already reported; used only by Export.
+	skipped map[string]struct{}
+}
+
+// NewMetricsExporter create exporter that push metrics collected by `coll`.
+func NewMetricsExporter(cfg *config.OTLP, coll collector.Collector) (*MetricsExporter, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(coll); err != nil {
+		return nil, fmt.Errorf("register collector error: %w", err)
+	}
+
+	return &MetricsExporter{
+		cfg:       cfg,
+		collector: coll,
+		gatherer:  registry,
+		client:    &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		startTime: time.Now(),
+		skipped:   make(map[string]struct{}),
+	}, nil
+}
+
+// Run export metrics every configured interval until context is done.
+func (m *MetricsExporter) Run(ctx context.Context) {
+	logger := slog.Default().With("url", m.cfg.URL)
+	logger.Info("starting otlp metrics export", "interval", m.cfg.Interval)
+
+	ticker := time.NewTicker(time.Duration(m.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Export(ctx); err != nil {
+			logger.Error("otlp metrics export error", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Export collect metrics and send it to endpoint.
+func (m *MetricsExporter) Export(ctx context.Context) error {
+	mfs, err := m.gatherer.Gather()
+	if err != nil {
+		// gather return partial result on errors; log it and push what we have
+		slog.Warn("gather metrics error", "err", err)
+	}
+
+	req := m.convert(mfs, time.Now())
+	if len(req.ResourceMetrics) == 0 {
+		return nil
+	}
+
+	return Post(ctx, m.client, m.cfg.URL, m.cfg.Headers, req)
+}
+
+// convert metric families into OTLP request; each device is separate resource.
+// Only counters, gauges and untyped metrics are exported; histograms and summaries are dropped.
+func (m *MetricsExporter) convert(mfs []*dto.MetricFamily, now time.Time) *ExportMetricsServiceRequest {
+	statuses := make(map[string]collector.DeviceStatus)
+	for _, s := range m.collector.DevicesStatus() {
+		statuses[s.Name] = s
+	}
+
+	resources := make(map[string]*ResourceMetrics)
+	// keep order of devices stable
+	var order []string
+
+	resourceFor := func(devName, devAddress string) *ResourceMetrics {
+		if rm, ok := resources[devName]; ok {
+			return rm
+		}
+
+		rm := &ResourceMetrics{
+			Resource:     resource(devName, devAddress, statuses),
+			ScopeMetrics: []ScopeMetrics{{Scope: Scope()}},
+		}
+		resources[devName] = rm
+		order = append(order, devName)
+
+		return rm
+	}
+
+	ts, start := unixNano(now), unixNano(m.startTime)
+
+	for _, mf := range mfs {
+		if !isSupportedType(mf.GetType()) {
+			if _, ok := m.skipped[mf.GetName()]; !ok {
+				m.skipped[mf.GetName()] = struct{}{}
+				slog.Warn("unsupported metric type for otlp export; metric is dropped",
+					"metric", mf.GetName(), "type", mf.GetType())
+			}
+
+			continue
+		}
+
+		// metrics of one family for each device
+		metrics := make(map[*ResourceMetrics]*Metric)
+
+		for _, pm := range mf.GetMetric() {
+			var (
+				value            float64
+				devName, devAddr string
+			)
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				value = pm.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				value = pm.GetGauge().GetValue()
+			default:
+				value = pm.GetUntyped().GetValue()
+			}
+
+			attrs := make([]KeyValue, 0, len(pm.GetLabel()))
+
+			for _, lp := range pm.GetLabel() {
+				switch lp.GetName() {
+				case "dev_name":
+					devName = lp.GetValue()
+				case "dev_address":
+					devAddr = lp.GetValue()
+				default:
+					attrs = append(attrs, String(lp.GetName(), lp.GetValue()))
+				}
+			}
+
+			rm := resourceFor(devName, devAddr)
+
+			metric, ok := metrics[rm]
+			if !ok {
+				metric = &Metric{Name: mf.GetName(), Description: mf.GetHelp()}
+				if mf.GetType() == dto.MetricType_COUNTER {
+					metric.Sum = &Sum{AggregationTemporality: aggregationTemporalityCumulative, IsMonotonic: true}
+				} else {
+					metric.Gauge = &Gauge{}
+				}
+
+				metrics[rm] = metric
+			}
+
+			dp := NumberDataPoint{Attributes: attrs, TimeUnixNano: ts, AsDouble: value}
+
+			if metric.Sum != nil {
+				dp.StartTimeUnixNano = start
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, dp)
+			} else {
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, dp)
+			}
+		}
+
+		for rm, metric := range metrics {
+			rm.ScopeMetrics[0].Metrics = append(rm.ScopeMetrics[0].Metrics, *metric)
+		}
+	}
+
+	req := &ExportMetricsServiceRequest{ResourceMetrics: make([]ResourceMetrics, 0, len(order))}
+	for _, name := range order {
+		req.ResourceMetrics = append(req.ResourceMetrics, *resources[name])
+	}
+
+	return req
+}
+
+func isSupportedType(t dto.MetricType) bool {
+	return t == dto.MetricType_COUNTER || t == dto.MetricType_GAUGE || t == dto.MetricType_UNTYPED
+}
+
+// resource create resource for device; metrics without device are assigned to exporter resource.
+func resource(devName, devAddress string, statuses map[string]collector.DeviceStatus) Resource {
+	attrs := []KeyValue{String("service.name", ServiceName)}
+
+	if devName == "" {
+		return Resource{attrs}
+	}
+
+	attrs = append(attrs, String("dev_name", devName), String("dev_address", devAddress))
+
+	if status, ok := statuses[devName]; ok {
+		for _, attr := range []KeyValue{
+			String("firmware_version", status.FirmwareVersion),
+			String("architecture", status.Architecture),
+			String("board", status.Board),
+		} {
+			if *attr.Value.StringValue != "" {
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+
+	return Resource{attrs}
+}
@@ -0,0 +1,103 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mikrotik-exporter/internal/collector"
+	"mikrotik-exporter/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testGaugeDesc = prometheus.NewDesc("mikrotik_test_temperature", "temperature",
+		[]string{"dev_name", "dev_address", "sensor"}, nil)
+	testCounterDesc = prometheus.NewDesc("mikrotik_test_bytes", "bytes",
+		[]string{"dev_name", "dev_address"}, nil)
+)
+
+// testCollector is collector.Collector returning static metrics for one device.
+type testCollector struct{}
+
+func (testCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- testGaugeDesc
+	ch <- testCounterDesc
+}
+
+func (testCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 40, "dev1", "10.0.0.1", "cpu")
+	ch <- prometheus.MustNewConstMetric(testCounterDesc, prometheus.CounterValue, 1000, "dev1", "10.0.0.1")
+}
+
+func (testCollector) DevicesStatus() []collector.DeviceStatus {
+	return []collector.DeviceStatus{
+		{Name: "dev1", FirmwareVersion: "7.15.2", Architecture: "arm64", Board: "RB5009"},
+	}
+}
+
+func (c testCollector) WithFilter(*collector.Filter) (prometheus.Collector, error) {
+	return c, nil
+}
+
+func (testCollector) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestMetricsExporter(t *testing.T) {
+	var received ExportMetricsServiceRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &config.OTLP{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer token"}, Timeout: 5}
+
+	exp, err := NewMetricsExporter(cfg, testCollector{})
+	require.NoError(t, err)
+	require.NoError(t, exp.Export(context.Background()))
+
+	require.Len(t, received.ResourceMetrics, 1)
+	rm := received.ResourceMetrics[0]
+
+	attrs := make(map[string]string)
+	for _, a := range rm.Resource.Attributes {
+		attrs[a.Key] = *a.Value.StringValue
+	}
+
+	assert.Equal(t, map[string]string{
+		"service.name": ServiceName, "dev_name": "dev1", "dev_address": "10.0.0.1",
+		"firmware_version": "7.15.2", "architecture": "arm64", "board": "RB5009",
+	}, attrs)
+
+	require.Len(t, rm.ScopeMetrics, 1)
+	metrics := rm.ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 2)
+
+	for _, m := range metrics {
+		switch m.Name {
+		case "mikrotik_test_temperature":
+			require.NotNil(t, m.Gauge)
+			require.Len(t, m.Gauge.DataPoints, 1)
+			assert.InDelta(t, 40.0, m.Gauge.DataPoints[0].AsDouble, 0.001)
+			assert.Equal(t, []KeyValue{String("sensor", "cpu")}, m.Gauge.DataPoints[0].Attributes)
+		case "mikrotik_test_bytes":
+			require.NotNil(t, m.Sum)
+			assert.True(t, m.Sum.IsMonotonic)
+			require.Len(t, m.Sum.DataPoints, 1)
+			assert.InDelta(t, 1000.0, m.Sum.DataPoints[0].AsDouble, 0.001)
+			assert.Empty(t, m.Sum.DataPoints[0].Attributes)
+		default:
+			t.Errorf("unexpected metric %s", m.Name)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package otlp
+
+//
+// otlp.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	pcVersion "github.com/prometheus/common/version"
+)
+
+const (
+	// ServiceName is value of `service.name` resource attribute.
+	ServiceName = "mikrotik-exporter"
+
+	maxErrorBodySize = 512
+)
+
+var ErrExportFailed = errors.New("export failed")
+
+// Types below are subset of OTLP messages in protobuf JSON mapping.
+type (
+	AnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+
+	KeyValue struct {
+		Key   string   `json:"key"`
+		Value AnyValue `json:"value"`
+	}
+
+	Resource struct {
+		Attributes []KeyValue `json:"attributes"`
+	}
+
+	InstrumentationScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+)
+
+// String create string attribute.
+func String(key, value string) KeyValue {
+	return KeyValue{key, AnyValue{StringValue: &value}}
+}
+
+// Int create integer attribute.
+func Int(key string, value int64) KeyValue {
+	v := strconv.FormatInt(value, 10)
+
+	return KeyValue{key, AnyValue{IntValue: &v}}
+}
+
+// Bool create boolean attribute.
+func Bool(key string, value bool) KeyValue {
+	return KeyValue{key, AnyValue{BoolValue: &value}}
+}
+
+// Scope return instrumentation scope of exporter.
+func Scope() InstrumentationScope {
+	return InstrumentationScope{Name: ServiceName, Version: pcVersion.Version}
+}
+
+// unixNano format time as OTLP fixed64 timestamp.
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// Post send `payload` encoded as json to OTLP/HTTP `url`.
+func Post(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", ServiceName+"/"+pcVersion.Version)
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request error: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 { //nolint:mnd
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+		return fmt.Errorf("%w: status %s: %s", ErrExportFailed, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
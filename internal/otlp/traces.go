@@ -0,0 +1,130 @@
+package otlp
+
+//
+// traces.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/tracing"
+)
+
+const (
+	// spanKindInternal is SPAN_KIND_INTERNAL.
+	spanKindInternal = 1
+	// statusCodeError is STATUS_CODE_ERROR.
+	statusCodeError = 2
+)
+
+type (
+	Status struct {
+		Message string `json:"message,omitempty"`
+		Code    int    `json:"code,omitempty"`
+	}
+
+	Span struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              int        `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []KeyValue `json:"attributes,omitempty"`
+		Status            *Status    `json:"status,omitempty"`
+	}
+
+	ScopeSpans struct {
+		Scope InstrumentationScope `json:"scope"`
+		Spans []Span               `json:"spans"`
+	}
+
+	ResourceSpans struct {
+		Resource   Resource     `json:"resource"`
+		ScopeSpans []ScopeSpans `json:"scopeSpans"`
+	}
+
+	ExportTraceServiceRequest struct {
+		ResourceSpans []ResourceSpans `json:"resourceSpans"`
+	}
+)
+
+// TraceExporter send spans to OTLP/HTTP traces endpoint.
+type TraceExporter struct {
+	cfg    *config.Tracing
+	client *http.Client
+}
+
+// NewTraceExporter create exporter for traces.
+func NewTraceExporter(cfg *config.Tracing) *TraceExporter {
+	return &TraceExporter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+	}
+}
+
+// Export implements tracing.Exporter interface.
+func (t *TraceExporter) Export(ctx context.Context, spans []*tracing.Span) error {
+	ospans := make([]Span, 0, len(spans))
+
+	for _, s := range spans {
+		span := Span{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: unixNano(s.Start),
+			EndTimeUnixNano:   unixNano(s.End),
+			Attributes:        make([]KeyValue, 0, len(s.Attributes)),
+		}
+
+		if s.ParentID.IsValid() {
+			span.ParentSpanID = s.ParentID.String()
+		}
+
+		for _, a := range s.Attributes {
+			span.Attributes = append(span.Attributes, attribute(a))
+		}
+
+		if s.Error != "" {
+			span.Status = &Status{Message: s.Error, Code: statusCodeError}
+		}
+
+		ospans = append(ospans, span)
+	}
+
+	req := ExportTraceServiceRequest{
+		ResourceSpans: []ResourceSpans{{
+			Resource:   Resource{[]KeyValue{String("service.name", ServiceName)}},
+			ScopeSpans: []ScopeSpans{{Scope: Scope(), Spans: ospans}},
+		}},
+	}
+
+	return Post(ctx, t.client, t.cfg.URL, t.cfg.Headers, &req)
+}
+
+// Shutdown implements tracing.Exporter interface.
+func (t *TraceExporter) Shutdown(_ context.Context) error {
+	return nil
+}
+
+func attribute(a tracing.Attribute) KeyValue {
+	switch v := a.Value.(type) {
+	case string:
+		return String(a.Key, v)
+	case int64:
+		return Int(a.Key, v)
+	case bool:
+		return Bool(a.Key, v)
+	case float64:
+		return KeyValue{a.Key, AnyValue{DoubleValue: &v}}
+	default:
+		return String(a.Key, "")
+	}
+}
@@ -0,0 +1,54 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mikrotik-exporter/internal/config"
+	"mikrotik-exporter/internal/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceExporter(t *testing.T) {
+	var received ExportTraceServiceRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	exporter := NewTraceExporter(&config.Tracing{URL: srv.URL, Timeout: 5})
+	tracer := tracing.Setup(exporter)
+
+	ctx, parent := tracing.Start(context.Background(), "collectFromDevice", tracing.String("dev_name", "dev1"))
+	_, child := tracing.Start(ctx, "Client.Run", tracing.Command([]string{"/system/identity/print"}))
+	child.SetError(errors.New("failed"))
+	child.Finish()
+	parent.Finish()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	require.Len(t, received.ResourceSpans, 1)
+	require.Len(t, received.ResourceSpans[0].ScopeSpans, 1)
+
+	spans := received.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "Client.Run", spans[0].Name)
+	assert.Equal(t, parent.SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, parent.TraceID.String(), spans[0].TraceID)
+	require.NotNil(t, spans[0].Status)
+	assert.Equal(t, "failed", spans[0].Status.Message)
+	assert.Equal(t, []KeyValue{String("command", "/system/identity/print")}, spans[0].Attributes)
+
+	assert.Equal(t, "collectFromDevice", spans[1].Name)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Nil(t, spans[1].Status)
+}
@@ -0,0 +1,140 @@
+package remotewrite
+
+//
+// encode.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type (
+	label struct {
+		name  string
+		value string
+	}
+
+	sample struct {
+		value     float64
+		timestamp int64
+	}
+
+	timeSeries struct {
+		labels  []label
+		samples []sample
+	}
+)
+
+// convert metric families into time series with one sample at `timestamp` (ms).
+func convert(mfs []*dto.MetricFamily, timestamp int64) []timeSeries {
+	var res []timeSeries
+
+	for _, mf := range mfs {
+		name := mf.GetName()
+
+		for _, m := range mf.GetMetric() {
+			ts := timestamp
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+
+			labels := make([]label, 0, len(m.GetLabel()))
+			for _, lp := range m.GetLabel() {
+				// empty label is the same as missing label; receivers may reject empty values
+				if lp.GetValue() != "" {
+					labels = append(labels, label{lp.GetName(), lp.GetValue()})
+				}
+			}
+
+			add := func(suffix string, value float64, extra ...label) {
+				lbls := make([]label, 0, len(labels)+len(extra)+1)
+				lbls = append(lbls, label{"__name__", name + suffix})
+				lbls = append(lbls, labels...)
+				lbls = append(lbls, extra...)
+				slices.SortFunc(lbls, func(a, b label) int { return strings.Compare(a.name, b.name) })
+
+				res = append(res, timeSeries{lbls, []sample{{value, ts}}})
+			}
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add("", m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add("", q.GetValue(), label{"quantile", formatFloat(q.GetQuantile())})
+				}
+
+				add("_sum", s.GetSampleSum())
+				add("_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					add("_bucket", float64(b.GetCumulativeCount()), label{"le", formatFloat(b.GetUpperBound())})
+				}
+
+				add("_bucket", float64(h.GetSampleCount()), label{"le", "+Inf"})
+				add("_sum", h.GetSampleSum())
+				add("_count", float64(h.GetSampleCount()))
+			}
+		}
+	}
+
+	return res
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// encodeWriteRequest encode series as prometheus.WriteRequest protobuf message.
+func encodeWriteRequest(series []timeSeries) []byte {
+	var buf []byte
+
+	for _, ts := range series {
+		var tsBuf []byte
+
+		for _, l := range ts.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType) //nolint:mnd
+			lb = protowire.AppendString(lb, l.value)
+
+			tsBuf = protowire.AppendTag(tsBuf, 1, protowire.BytesType)
+			tsBuf = protowire.AppendBytes(tsBuf, lb)
+		}
+
+		for _, s := range ts.samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+			sb = protowire.AppendTag(sb, 2, protowire.VarintType) //nolint:mnd
+			sb = protowire.AppendVarint(sb, uint64(s.timestamp))  //nolint:gosec
+
+			tsBuf = protowire.AppendTag(tsBuf, 2, protowire.BytesType) //nolint:mnd
+			tsBuf = protowire.AppendBytes(tsBuf, sb)
+		}
+
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, tsBuf)
+	}
+
+	return buf
+}
@@ -0,0 +1,222 @@
+package remotewrite
+
+//
+// remotewrite.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"mikrotik-exporter/internal/config"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	pcVersion "github.com/prometheus/common/version"
+)
+
+const maxErrorBodySize = 512
+
+var ErrPushFailed = errors.New("push failed")
+
+// Pusher periodically gather metrics and push it to remote_write endpoint.
+type Pusher struct {
+	cfg      *config.RemoteWrite
+	gatherer prometheus.Gatherer
+	client   *http.Client
+
+	// buffer keep encoded and compressed batches that were not sent yet.
+	buffer [][]byte
+	mu     sync.Mutex
+
+	// retryDelay is delay before first retry; doubled on each next retry.
+	retryDelay time.Duration
+}
+
+// New create Pusher that push metrics gathered by `gatherer`.
+func New(cfg *config.RemoteWrite, gatherer prometheus.Gatherer) *Pusher {
+	return &Pusher{
+		cfg:        cfg,
+		gatherer:   gatherer,
+		client:     &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		retryDelay: time.Second,
+	}
+}
+
+// Run push metrics every configured interval until context is done.
+func (p *Pusher) Run(ctx context.Context) {
+	logger := slog.Default().With("url", p.cfg.URL)
+	logger.Info("starting remote write", "interval", p.cfg.Interval)
+
+	ticker := time.NewTicker(time.Duration(p.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Push(ctx); err != nil {
+			logger.Error("remote write error", "err", err, "pending_batches", p.Pending())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Push gather metrics, append it to buffer and send all buffered batches.
+func (p *Pusher) Push(ctx context.Context) error {
+	mfs, err := p.gatherer.Gather()
+	if err != nil {
+		// gather return partial result on errors; log it and push what we have
+		slog.Warn("gather metrics error", "err", err)
+	}
+
+	series := convert(mfs, time.Now().UnixMilli())
+	if len(series) > 0 {
+		p.enqueue(snappy.Encode(nil, encodeWriteRequest(series)))
+	}
+
+	return p.flush(ctx)
+}
+
+// Pending return number of batches waiting to be sent.
+func (p *Pusher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.buffer)
+}
+
+func (p *Pusher) enqueue(batch []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buffer = append(p.buffer, batch)
+
+	if drop := len(p.buffer) - p.cfg.BufferSize; drop > 0 {
+		slog.Warn("remote write buffer full; dropping oldest batches", "dropped", drop)
+
+		p.buffer = p.buffer[drop:]
+	}
+}
+
+// flush send buffered batches in order; stop on first batch that can't be sent.
+func (p *Pusher) flush(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		if len(p.buffer) == 0 {
+			p.mu.Unlock()
+
+			return nil
+		}
+
+		batch := p.buffer[0]
+		p.mu.Unlock()
+
+		err := p.sendWithRetries(ctx, batch)
+
+		var perr permanentError
+		if err != nil && !errors.As(err, &perr) {
+			return err
+		}
+
+		p.mu.Lock()
+		p.buffer = p.buffer[1:]
+		p.mu.Unlock()
+
+		if err != nil {
+			// endpoint rejected data; retrying will not help
+			slog.Error("remote write rejected batch", "err", err)
+		}
+	}
+}
+
+func (p *Pusher) sendWithRetries(ctx context.Context, batch []byte) error {
+	delay := p.retryDelay
+
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		err = p.send(ctx, batch)
+		if err == nil {
+			return nil
+		}
+
+		var perr permanentError
+		if errors.As(err, &perr) || attempt >= *p.cfg.MaxRetries {
+			return err
+		}
+
+		slog.Debug("remote write failed; retrying", "err", err, "attempt", attempt+1)
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func (p *Pusher) send(ctx context.Context, batch []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(batch))
+	if err != nil {
+		return permanentError{fmt.Errorf("create request error: %w", err)}
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "mikrotik-exporter/"+pcVersion.Version)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	if p.cfg.Username != "" {
+		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request error: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 { //nolint:mnd
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	err = fmt.Errorf("%w: status %s: %s", ErrPushFailed, resp.Status, bytes.TrimSpace(body))
+
+	// retry only server errors and rate limiting
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests { //nolint:mnd
+		return err
+	}
+
+	return permanentError{err}
+}
+
+// permanentError is error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (p permanentError) Error() string {
+	return p.err.Error()
+}
+
+func (p permanentError) Unwrap() error {
+	return p.err
+}
@@ -0,0 +1,218 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"mikrotik-exporter/internal/config"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// receiver is remote_write endpoint that decode and store received series.
+type receiver struct {
+	series []map[string]string
+	values []float64
+	fail   atomic.Int32
+	mu     sync.Mutex
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rc.fail.Load() > 0 {
+		rc.fail.Add(-1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	if r.Header.Get("Content-Encoding") != "snappy" ||
+		r.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "invalid content", http.StatusBadRequest)
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, ts := range fields(data, 1) {
+		labels := make(map[string]string)
+
+		for _, l := range fields(ts, 1) {
+			labels[string(fields(l, 1)[0])] = string(fields(l, 2)[0])
+		}
+
+		rc.series = append(rc.series, labels)
+
+		for _, s := range fields(ts, 2) {
+			v, _ := protowire.ConsumeFixed64(s[1:])
+			rc.values = append(rc.values, math.Float64frombits(v))
+		}
+	}
+}
+
+// fields return raw content of fields with number `num`; for fixed64 fields content
+// contains tag byte.
+func fields(b []byte, num protowire.Number) [][]byte {
+	var res [][]byte
+
+	for len(b) > 0 {
+		n, typ, tagLen := protowire.ConsumeTag(b)
+		valLen := protowire.ConsumeFieldValue(n, typ, b[tagLen:])
+
+		if n == num {
+			if typ == protowire.BytesType {
+				v, _ := protowire.ConsumeBytes(b[tagLen:])
+				res = append(res, v)
+			} else {
+				res = append(res, b[:tagLen+valLen])
+			}
+		}
+
+		b = b[tagLen+valLen:]
+	}
+
+	return res
+}
+
+func newTestPusher(t *testing.T, url string) (*Pusher, prometheus.Gauge) {
+	t.Helper()
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "mikrotik_test_value",
+		Help:        "test",
+		ConstLabels: prometheus.Labels{"dev_name": "dev1"},
+	})
+	gauge.Set(42)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(gauge))
+
+	cfg := &config.RemoteWrite{URL: url, Username: "user", Password: "pass", MaxRetries: new(1), BufferSize: 2}
+	p := New(cfg, reg)
+	p.retryDelay = 0
+
+	return p, gauge
+}
+
+func TestPush(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	p, _ := newTestPusher(t, srv.URL)
+
+	require.NoError(t, p.Push(context.Background()))
+	assert.Equal(t, 0, p.Pending())
+
+	require.Len(t, rc.series, 1)
+	assert.Equal(t, map[string]string{"__name__": "mikrotik_test_value", "dev_name": "dev1"}, rc.series[0])
+	assert.InDelta(t, 42.0, rc.values[0], 0.0001)
+}
+
+func TestPushSkipEmptyLabels(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	p, _ := newTestPusher(t, srv.URL)
+
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "mikrotik_test_labels", Help: "test"},
+		[]string{"comment", "name"})
+	gauge.WithLabelValues("", "eth1").Set(1)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(gauge))
+
+	p.gatherer = reg
+
+	require.NoError(t, p.Push(context.Background()))
+	require.Len(t, rc.series, 1)
+	assert.Equal(t, map[string]string{"__name__": "mikrotik_test_labels", "name": "eth1"}, rc.series[0])
+}
+
+func TestPushNoRetries(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	p, _ := newTestPusher(t, srv.URL)
+	p.cfg.MaxRetries = new(0)
+
+	// failed request is not retried; batch is kept in buffer
+	rc.fail.Store(1)
+	require.Error(t, p.Push(context.Background()))
+	assert.Equal(t, 1, p.Pending())
+	assert.Equal(t, int32(0), rc.fail.Load())
+}
+
+func TestPushRetriesAndBuffer(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	p, gauge := newTestPusher(t, srv.URL)
+
+	// first failure is retried
+	rc.fail.Store(1)
+	require.NoError(t, p.Push(context.Background()))
+	require.Len(t, rc.series, 1)
+
+	// endpoint unavailable - batches are buffered; oldest dropped
+	rc.fail.Store(100)
+
+	for i := range 3 {
+		gauge.Set(float64(i))
+		require.Error(t, p.Push(context.Background()))
+	}
+
+	assert.Equal(t, 2, p.Pending())
+
+	// endpoint available again - buffered batches are sent in order; buffer still
+	// keep only 2 batches
+	rc.fail.Store(0)
+	gauge.Set(3)
+	require.NoError(t, p.Push(context.Background()))
+	assert.Equal(t, 0, p.Pending())
+
+	assert.Equal(t, []float64{42, 2, 3}, rc.values)
+}
+
+func TestPushRejected(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	p, _ := newTestPusher(t, srv.URL)
+	p.cfg.Password = "invalid"
+
+	// rejected batches are dropped
+	require.NoError(t, p.Push(context.Background()))
+	assert.Equal(t, 0, p.Pending())
+	assert.Empty(t, rc.series)
+}
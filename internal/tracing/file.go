@@ -0,0 +1,93 @@
+package tracing
+
+//
+// file.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileSpan is json representation of span.
+type fileSpan struct {
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	ParentID   string         `json:"parent_span_id,omitempty"`
+	Name       string         `json:"name"`
+	Start      time.Time      `json:"start"`
+	End        time.Time      `json:"end"`
+	Duration   float64        `json:"duration_seconds"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// FileExporter write spans into file as json lines (one span per line).
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileExporter create exporter that append spans to `filename`.
+func NewFileExporter(filename string) (*FileExporter, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640) //nolint:mnd
+	if err != nil {
+		return nil, fmt.Errorf("open file error: %w", err)
+	}
+
+	return &FileExporter{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Export implements Exporter interface.
+func (f *FileExporter) Export(_ context.Context, spans []*Span) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, s := range spans {
+		fs := fileSpan{
+			TraceID:  s.TraceID.String(),
+			SpanID:   s.SpanID.String(),
+			Name:     s.Name,
+			Start:    s.Start,
+			End:      s.End,
+			Duration: s.End.Sub(s.Start).Seconds(),
+			Error:    s.Error,
+		}
+
+		if s.ParentID.IsValid() {
+			fs.ParentID = s.ParentID.String()
+		}
+
+		if len(s.Attributes) > 0 {
+			fs.Attributes = make(map[string]any, len(s.Attributes))
+			for _, a := range s.Attributes {
+				fs.Attributes[a.Key] = a.Value
+			}
+		}
+
+		if err := f.enc.Encode(&fs); err != nil {
+			return fmt.Errorf("write span error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown implements Exporter interface.
+func (f *FileExporter) Shutdown(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close file error: %w", err)
+	}
+
+	return nil
+}
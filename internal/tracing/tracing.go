@@ -0,0 +1,304 @@
+// Package tracing is minimal tracer that export spans via OTLP/HTTP or to file. It is not
+// OpenTelemetry SDK: there is no sampling, propagation nor instrumentation libraries support.
+// Finished spans are buffered (up to maxQueueSize) and exported in batches; when buffer is full
+// (i.e. exporter is too slow or unavailable) new spans are dropped.
+package tracing
+
+//
+// tracing.go
+//
+// Distributed under terms of the GPLv3 license.
+//
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxBatchSize is number of finished spans that trigger export.
+	maxBatchSize = 512
+	// exportInterval is maximal time spans wait for export.
+	exportInterval = 5 * time.Second
+	// maxQueueSize is maximal number of finished spans waiting for export; new spans are
+	// dropped when buffer is full.
+	maxQueueSize = 2048
+)
+
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid return true when span id is set.
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// Attribute is span attribute; value is string, int64, float64 or bool.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Span is single traced operation.
+type Span struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	// Error is error message when operation failed.
+	Error string
+
+	tracer *Tracer
+}
+
+// SetAttributes add attributes to span. Safe to call on nil span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.Attributes = append(s.Attributes, attrs...)
+}
+
+// SetError mark span as failed when err is not nil. Safe to call on nil span.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.Error = err.Error()
+}
+
+// Finish end span and queue it for export. Safe to call on nil span.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+
+	s.End = time.Now()
+	s.tracer.enqueue(s)
+}
+
+// --------------------------------------------
+
+// Exporter send finished spans to its destination.
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer create spans and export finished spans in batches.
+type Tracer struct {
+	exporter Exporter
+
+	mu     sync.Mutex
+	buffer []*Span
+	// dropped is number of spans dropped because buffer was full since last flush.
+	dropped int
+
+	flushCh chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+var (
+	globalTracer   *Tracer
+	globalTracerMu sync.RWMutex
+)
+
+// Setup enable tracing with given exporter. Returned tracer must be shut down on exit.
+func Setup(exporter Exporter) *Tracer {
+	t := &Tracer{
+		exporter: exporter,
+		flushCh:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go t.run()
+
+	globalTracerMu.Lock()
+	globalTracer = t
+	globalTracerMu.Unlock()
+
+	return t
+}
+
+// Shutdown disable tracing, export pending spans and close exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	globalTracerMu.Lock()
+	if globalTracer == t {
+		globalTracer = nil
+	}
+	globalTracerMu.Unlock()
+
+	close(t.done)
+	<-t.stopped
+
+	t.flush(ctx)
+
+	return t.exporter.Shutdown(ctx) //nolint:wrapcheck
+}
+
+func (t *Tracer) run() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		case <-t.flushCh:
+		}
+
+		t.flush(context.Background())
+	}
+}
+
+func (t *Tracer) flush(ctx context.Context) {
+	t.mu.Lock()
+	spans := t.buffer
+	dropped := t.dropped
+	t.buffer = nil
+	t.dropped = 0
+	t.mu.Unlock()
+
+	if dropped > 0 {
+		slog.Warn("tracing buffer full; spans dropped", "dropped", dropped)
+	}
+
+	for batch := range slices.Chunk(spans, maxBatchSize) {
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			slog.Error("export spans error", "err", err, "spans", len(batch))
+		}
+	}
+}
+
+func (t *Tracer) enqueue(s *Span) {
+	t.mu.Lock()
+
+	if len(t.buffer) >= maxQueueSize {
+		t.dropped++
+		t.mu.Unlock()
+
+		return
+	}
+
+	t.buffer = append(t.buffer, s)
+	full := len(t.buffer) >= maxBatchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// --------------------------------------------
+
+type spanCtxKey struct{}
+
+// Start create new span as child of span from ctx. When tracing is disabled return nil span.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	globalTracerMu.RLock()
+	tracer := globalTracer
+	globalTracerMu.RUnlock()
+
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: attrs,
+		tracer:     tracer,
+	}
+
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		fillRandom(span.TraceID[:])
+	}
+
+	fillRandom(span.SpanID[:])
+
+	return context.WithValue(ctx, spanCtxKey{}, span), span
+}
+
+// FromContext return current span from context or nil.
+func FromContext(ctx context.Context) *Span {
+	if s, ok := ctx.Value(spanCtxKey{}).(*Span); ok {
+		return s
+	}
+
+	return nil
+}
+
+func fillRandom(b []byte) {
+	for i := range b {
+		b[i] = byte(rand.Uint32()) //nolint:gosec
+	}
+}
+
+// --------------------------------------------
+
+// String create string attribute.
+func String(key, value string) Attribute {
+	return Attribute{key, value}
+}
+
+// Int create integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{key, int64(value)}
+}
+
+// Command create attribute with RouterOS API command; values of password-like arguments
+// are stripped.
+func Command(sentence []string) Attribute {
+	words := make([]string, len(sentence))
+
+	for i, word := range sentence {
+		if strings.HasPrefix(word, "=") {
+			if key, _, ok := strings.Cut(word[1:], "="); ok && isSecretKey(key) {
+				word = "=" + key + "=***"
+			}
+		}
+
+		words[i] = word
+	}
+
+	return String("command", strings.Join(words, " "))
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+
+	return strings.Contains(key, "password") || strings.Contains(key, "secret") ||
+		key == "response" || strings.Contains(key, "passphrase") || strings.Contains(key, "key")
+}
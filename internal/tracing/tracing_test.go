@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartDisabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "test")
+	assert.Nil(t, span)
+	assert.Nil(t, FromContext(ctx))
+
+	// nil span is safe to use
+	span.SetAttributes(String("a", "b"))
+	span.SetError(errors.New("error"))
+	span.Finish()
+}
+
+func TestFileExporter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "spans.json")
+
+	exporter, err := NewFileExporter(filename)
+	require.NoError(t, err)
+
+	tracer := Setup(exporter)
+
+	ctx, parent := Start(context.Background(), "parent", String("dev_name", "dev1"))
+	_, child := Start(ctx, "child", Command([]string{"/login", "=name=admin", "=password=secret"}))
+	child.SetError(errors.New("failed"))
+	child.Finish()
+	parent.Finish()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	f, err := os.Open(filename)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var spans []fileSpan
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s fileSpan
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+
+		spans = append(spans, s)
+	}
+
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "failed", spans[0].Error)
+	assert.Equal(t, "/login =name=admin =password=***", spans[0].Attributes["command"])
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, "dev1", spans[1].Attributes["dev_name"])
+	assert.Empty(t, spans[1].ParentID)
+
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentID)
+}
+
+type countExporter struct {
+	batches []int
+}
+
+func (c *countExporter) Export(_ context.Context, spans []*Span) error {
+	c.batches = append(c.batches, len(spans))
+
+	return nil
+}
+
+func (c *countExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestTracerBufferLimit(t *testing.T) {
+	exporter := &countExporter{}
+	// tracer without background export
+	tracer := &Tracer{exporter: exporter, flushCh: make(chan struct{}, 1)}
+
+	for range maxQueueSize + 10 {
+		(&Span{tracer: tracer}).Finish()
+	}
+
+	assert.Len(t, tracer.buffer, maxQueueSize)
+	assert.Equal(t, 10, tracer.dropped)
+
+	tracer.flush(context.Background())
+
+	assert.Empty(t, tracer.buffer)
+	assert.Zero(t, tracer.dropped)
+	assert.Equal(t, []int{maxBatchSize, maxBatchSize, maxBatchSize, maxBatchSize}, exporter.batches)
+}
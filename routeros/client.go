@@ -12,6 +12,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"mikrotik-exporter/routeros/proto"
 )
@@ -24,6 +25,7 @@ type Client struct {
 	Queue   int
 	mu      sync.Mutex
 	closing bool
+	runHook RunHook
 }
 
 // NewClient returns a new Client over rwc. Login must be called.
@@ -111,6 +113,19 @@ func (c *Client) Close() {
 	c.rwc.Close()
 }
 
+// Quit sends the /quit command and closes the connection. When underlying connection
+// supports deadlines, waits for reply at most `timeout`.
+func (c *Client) Quit(timeout time.Duration) {
+	if conn, ok := c.rwc.(interface{ SetDeadline(t time.Time) error }); ok {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	// device respond with !fatal and close connection
+	_, _ = c.Run("/quit")
+
+	c.Close()
+}
+
 // Login runs the /login command. Dial and DialTLS call this automatically.
 func (c *Client) Login(username, password string) error {
 	r, err := c.Run("/login", "=name="+username, "=password="+password)
@@ -2,13 +2,28 @@ package routeros
 
 import "fmt"
 
+// RunHook is called before each command; returned function (if not nil) is called
+// with command result.
+type RunHook func(sentence []string) func(reply *Reply, err error)
+
+// SetRunHook set hook called on each command; nil disable hook.
+func (c *Client) SetRunHook(hook RunHook) {
+	c.runHook = hook
+}
+
 // Run simply calls RunArgs().
 func (c *Client) Run(sentence ...string) (*Reply, error) {
 	return c.RunArgs(sentence)
 }
 
 // RunArgs sends a sentence to the RouterOS device and waits for the reply.
-func (c *Client) RunArgs(sentence []string) (*Reply, error) {
+func (c *Client) RunArgs(sentence []string) (reply *Reply, err error) {
+	if c.runHook != nil {
+		if done := c.runHook(sentence); done != nil {
+			defer func() { done(reply, err) }()
+		}
+	}
+
 	c.w.BeginSentence()
 
 	for _, word := range sentence {
@@ -0,0 +1,93 @@
+package routeros
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	ErrInvalidTLSVersion = errors.New("invalid TLS version")
+	ErrInvalidCAFile     = errors.New("no certificates found in CA file")
+	ErrMissingKeyFile    = errors.New("both certificate and key file must be given")
+)
+
+// TLSOptions configure TLS connection to a RouterOS device.
+type TLSOptions struct {
+	// CAFile is path to PEM file with CA certificates used to verify device certificate.
+	// System pool is used when empty.
+	CAFile string
+	// CertFile and KeyFile are paths to PEM files with client certificate and key.
+	CertFile string
+	KeyFile  string
+	// ServerName is used to verify device certificate instead of address.
+	ServerName string
+	// MinVersion is minimal accepted TLS version: 1.0, 1.1, 1.2 or 1.3.
+	MinVersion string
+	// Insecure disable verification of device certificate.
+	Insecure bool
+}
+
+// NewTLSConfig create tls.Config for DialTLS and DialTLSContext from `opts`.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: opts.Insecure, //nolint:gosec
+		ServerName:         opts.ServerName,
+	}
+
+	if opts.MinVersion != "" {
+		version, err := ParseTLSVersion(opts.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.MinVersion = version
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file error: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("load %s error: %w", opts.CAFile, ErrInvalidCAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, ErrMissingKeyFile
+		}
+
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate error: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// ParseTLSVersion convert version name (1.0, 1.1, 1.2, 1.3) to tls.Version* value.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidTLSVersion, version)
+}